@@ -0,0 +1,37 @@
+# fish completion for toolbox
+
+function __toolbox_complete
+    set -l args (commandline -opc)
+    set -l current (commandline -ct)
+    set -l toolbox $args[1]
+    set -e args[1]
+
+    set -l completions (command $toolbox __complete $args "$current" 2>/dev/null)
+    test (count $completions) -eq 0; and return
+
+    set -l directive $completions[-1]
+    set -e completions[-1]
+
+    for completion in $completions
+        echo $completion
+    end
+
+    switch $directive
+        case :directories :files
+            set -l prefix (string match -r -- '^-[^=]*=' "$current")
+            set -l path (string replace -r -- '^-[^=]*=' '' "$current")
+            set -l paths
+
+            if test $directive = :directories
+                set paths (__fish_complete_directories "$path")
+            else
+                set paths (__fish_complete_path "$path")
+            end
+
+            for candidate in $paths
+                echo "$prefix$candidate"
+            end
+    end
+end
+
+complete -c toolbox -f -a '(__toolbox_complete)'
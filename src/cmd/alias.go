@@ -0,0 +1,140 @@
+/*
+ * Copyright © 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/containers/toolbox/pkg/config"
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage aliases for toolbox command lines",
+	RunE:  alias,
+}
+
+func init() {
+	aliasCmd.SetHelpFunc(aliasHelp)
+	rootCmd.AddCommand(aliasCmd)
+}
+
+func alias(cmd *cobra.Command, args []string) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	var builder strings.Builder
+
+	if len(args) == 0 {
+		fmt.Fprintf(&builder, "missing command\n")
+	} else {
+		fmt.Fprintf(&builder, "unknown command \"%s\" for \"%s alias\"\n", args[0], executableBase)
+	}
+
+	fmt.Fprintf(&builder, "Run '%s alias --help' for usage.", executableBase)
+
+	errMsg := builder.String()
+	return errors.New(errMsg)
+}
+
+func aliasHelp(cmd *cobra.Command, args []string) {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			fmt.Fprintf(os.Stderr, "Error: this is not a toolbox container\n")
+			return
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return
+		}
+
+		return
+	}
+
+	if err := utils.ShowManual("toolbox-alias"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return
+	}
+}
+
+// expandAlias replaces the alias named by the first non-option argument in
+// args, if any, with the command line that it expands to. The global options
+// before it are kept. Invalid aliases are ignored with a warning.
+func expandAlias(aliases map[string]string, args []string) ([]string, error) {
+	aliases = getValidAliases(aliases)
+
+	index := getCommandIndex(args)
+	if index == -1 {
+		return args, nil
+	}
+
+	name := args[index]
+
+	definition, ok := aliases[name]
+	if !ok {
+		return args, nil
+	}
+
+	expanded, err := config.ExpandAlias(definition, args[index+1:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand alias %s: %w", name, err)
+	}
+
+	var expandedArgs []string
+	expandedArgs = append(expandedArgs, args[:index]...)
+	expandedArgs = append(expandedArgs, expanded...)
+	return expandedArgs, nil
+}
+
+// getValidAliases returns the aliases that can be used. An alias with the
+// name of a built-in command is left out, so that the built-in command wins.
+// This can happen when a newer version of Toolbox adds a command, and it
+// mustn't break every command, including the entry point of the containers.
+func getValidAliases(aliases map[string]string) map[string]string {
+	validAliases := make(map[string]string)
+
+	for name, definition := range aliases {
+		if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \t\n") {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring alias with invalid name '%s'\n", name)
+			continue
+		}
+
+		if isBuiltInCommand(name) {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring alias %s, because it shadows a built-in command\n", name)
+			continue
+		}
+
+		validAliases[name] = definition
+	}
+
+	return validAliases
+}
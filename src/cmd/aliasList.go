@@ -0,0 +1,95 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the aliases defined in the configuration",
+	RunE:  aliasList,
+}
+
+func init() {
+	aliasListCmd.SetHelpFunc(aliasListHelp)
+	aliasCmd.AddCommand(aliasListCmd)
+}
+
+func aliasList(cmd *cobra.Command, args []string) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	if len(toolboxConfig.Aliases) == 0 {
+		return nil
+	}
+
+	var names []string
+	for name := range toolboxConfig.Aliases {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "%s\t%s\n", "ALIAS", "COMMAND")
+
+	for _, name := range names {
+		fmt.Fprintf(writer, "%s\t%s\n", name, toolboxConfig.Aliases[name])
+	}
+
+	writer.Flush()
+	return nil
+}
+
+func aliasListHelp(cmd *cobra.Command, args []string) {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			fmt.Fprintf(os.Stderr, "Error: this is not a toolbox container\n")
+			return
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return
+		}
+
+		return
+	}
+
+	if err := utils.ShowManual("toolbox-alias-list"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandAlias(t *testing.T) {
+	aliases := map[string]string{
+		"b": "run -c dev-f35 make -j8",
+	}
+
+	testCases := []struct {
+		name     string
+		aliases  map[string]string
+		args     []string
+		expanded []string
+		err      string
+	}{
+		{
+			name:     "Alias",
+			aliases:  aliases,
+			args:     []string{"b", "install"},
+			expanded: []string{"run", "-c", "dev-f35", "make", "-j8", "install"},
+		},
+		{
+			name:     "Alias after global options",
+			aliases:  aliases,
+			args:     []string{"--log-level", "debug", "b"},
+			expanded: []string{"--log-level", "debug", "run", "-c", "dev-f35", "make", "-j8"},
+		},
+		{
+			name:     "Alias as an argument",
+			aliases:  aliases,
+			args:     []string{"enter", "b"},
+			expanded: []string{"enter", "b"},
+		},
+		{
+			name:     "Alias shadowing a built-in command",
+			aliases:  map[string]string{"run": "enter", "b": "list"},
+			args:     []string{"run", "ls"},
+			expanded: []string{"run", "ls"},
+		},
+		{
+			name:     "Invalid alias name",
+			aliases:  map[string]string{"-b": "enter", "b": "list"},
+			args:     []string{"b"},
+			expanded: []string{"list"},
+		},
+		{
+			name:    "Invalid alias",
+			aliases: map[string]string{"b": "run 'ls"},
+			args:    []string{"b"},
+			err:     "failed to expand alias b: unterminated quote '",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expanded, err := expandAlias(tc.aliases, tc.args)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expanded, expanded)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright © 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	buildFlags struct {
+		file string
+		tag  string
+	}
+
+	// buildLabels holds the labels added to every image built with the
+	// 'build' command, so that it's accepted as a toolbox image
+	buildLabels = map[string]string{
+		"com.github.containers.toolbox": "true",
+	}
+)
+
+var buildCmd = &cobra.Command{
+	Use:         "build",
+	Short:       "Build a toolbox image from a Containerfile",
+	RunE:        build,
+	Annotations: map[string]string{completionAnnotation: completionDirectories},
+}
+
+func init() {
+	flags := buildCmd.Flags()
+
+	flags.StringVarP(&buildFlags.file,
+		"file",
+		"f",
+		"",
+		"Use FILE as the Containerfile instead of the one in the build context")
+
+	flags.StringVarP(&buildFlags.tag,
+		"tag",
+		"t",
+		"",
+		"Name the built image NAME instead of after the build context")
+
+	markFlagCompletion(flags, "file", completionFiles)
+
+	buildCmd.SetHelpFunc(buildHelp)
+	rootCmd.AddCommand(buildCmd)
+}
+
+func build(cmd *cobra.Command, args []string) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	contextDir := "."
+	if len(args) != 0 {
+		contextDir = args[0]
+	}
+
+	contextDirAbs, err := filepath.Abs(contextDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve build context %s: %w", contextDir, err)
+	}
+
+	if fileInfo, err := os.Stat(contextDirAbs); err != nil || !fileInfo.IsDir() {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "invalid argument for 'CONTEXT'\n")
+		fmt.Fprintf(&builder, "Build context %s is not a directory\n", contextDir)
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	image := buildFlags.tag
+	if image == "" {
+		image = sanitizeImageName(filepath.Base(contextDirAbs), "toolbox-image")
+	}
+
+	imageFull := image
+	if !utils.ImageReferenceHasDomain(image) {
+		imageFull = "localhost/" + image
+	}
+
+	logrus.Debugf("Building image %s from context %s", imageFull, contextDirAbs)
+
+	if err := podman.Build(contextDirAbs,
+		buildFlags.file,
+		imageFull,
+		buildLabels,
+		os.Stdout,
+		os.Stderr); err != nil {
+		return fmt.Errorf("failed to build image %s", imageFull)
+	}
+
+	fmt.Printf("Built image: %s\n", imageFull)
+	fmt.Printf("Create a toolbox container with: %s create --image %s\n", executableBase, image)
+
+	return nil
+}
+
+func buildHelp(cmd *cobra.Command, args []string) {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			fmt.Fprintf(os.Stderr, "Error: this is not a toolbox container\n")
+			return
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return
+		}
+
+		return
+	}
+
+	if err := utils.ShowManual("toolbox-build"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return
+	}
+}
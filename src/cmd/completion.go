@@ -0,0 +1,578 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// completionAnnotation marks flags and commands with the kind of values
+	// that their arguments are completed with
+	completionAnnotation = "com.github.containers.toolbox.completion"
+
+	completionCommandLine = "command-line"
+	completionCommands    = "commands"
+	completionContainers  = "containers"
+	completionDirectories = "directories"
+	completionDistros     = "distros"
+	completionFiles       = "files"
+	completionImages      = "images"
+	completionLogFormats  = "log-formats"
+	completionLogLevels   = "log-levels"
+	completionReleases    = "releases"
+
+	// The last line printed by '__complete' tells the shell whether it
+	// should also complete file or directory names
+	completionDirectiveDirectories = ":directories"
+	completionDirectiveFiles       = ":files"
+	completionDirectiveNoFiles     = ":nofiles"
+)
+
+var completionCmd = &cobra.Command{
+	Use:       "completion",
+	Short:     "Generate a shell completion script",
+	RunE:      completion,
+	ValidArgs: []string{"bash", "fish", "zsh"},
+}
+
+var completeCmd = &cobra.Command{
+	Use:                "__complete",
+	Short:              "Complete a partial command line",
+	Hidden:             true,
+	DisableFlagParsing: true,
+	RunE:               complete,
+}
+
+func init() {
+	completionCmd.SetHelpFunc(completionHelp)
+	rootCmd.AddCommand(completionCmd)
+
+	rootCmd.AddCommand(completeCmd)
+}
+
+func completion(cmd *cobra.Command, args []string) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	if len(args) != 1 {
+		var builder strings.Builder
+
+		if len(args) == 0 {
+			fmt.Fprintf(&builder, "missing argument for \"completion\"\n")
+		} else {
+			fmt.Fprintf(&builder, "too many arguments for \"completion\"\n")
+		}
+
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	var script string
+
+	switch args[0] {
+	case "bash":
+		script = completionScriptBash
+	case "fish":
+		script = completionScriptFish
+	case "zsh":
+		script = completionScriptZsh
+	default:
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "invalid argument for 'SHELL'\n")
+		fmt.Fprintf(&builder, "Supported shells are %s.\n", strings.Join(cmd.ValidArgs, ", "))
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
+	fmt.Print(script)
+	return nil
+}
+
+func completionHelp(cmd *cobra.Command, args []string) {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			fmt.Fprintf(os.Stderr, "Error: this is not a toolbox container\n")
+			return
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return
+		}
+
+		return
+	}
+
+	if err := utils.ShowManual("toolbox-completion"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return
+	}
+}
+
+// complete is used by the shell completion scripts. The arguments are the
+// words of the command line after the executable, up to and including the
+// word being completed. It prints the matching completions, one per line,
+// followed by a directive for the shell.
+func complete(cmd *cobra.Command, args []string) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	toComplete := ""
+	if len(args) != 0 {
+		toComplete = args[len(args)-1]
+		args = args[:len(args)-1]
+	}
+
+	completions, directive := completeCommandLine(args, toComplete)
+
+	for _, completion := range completions {
+		if strings.HasPrefix(completion, toComplete) {
+			fmt.Println(completion)
+		}
+	}
+
+	fmt.Println(directive)
+	return nil
+}
+
+// completeCommandLine walks the command tree along words, and returns the
+// completions for the word after them.
+func completeCommandLine(words []string, toComplete string) ([]string, string) {
+	command := rootCmd
+	flagValues := make(map[string]string)
+
+	var positionalArgs []string
+	var valueFlag *pflag.Flag
+
+	for _, word := range words {
+		if valueFlag != nil {
+			flagValues[valueFlag.Name] = word
+			valueFlag = nil
+			continue
+		}
+
+		// Everything after the command to run belongs to it
+		if len(positionalArgs) != 0 && command.Annotations[completionAnnotation] == completionCommandLine {
+			positionalArgs = append(positionalArgs, word)
+			continue
+		}
+
+		if strings.HasPrefix(word, "-") && word != "-" {
+			flag := completionLookupFlag(command, word)
+			if flag == nil {
+				continue
+			}
+
+			if index := strings.Index(word, "="); index != -1 {
+				flagValues[flag.Name] = word[index+1:]
+			} else if flag.NoOptDefVal == "" {
+				valueFlag = flag
+			}
+
+			continue
+		}
+
+		if len(positionalArgs) == 0 {
+			if subCommand := completionFindCommand(command, word); subCommand != nil {
+				command = subCommand
+				continue
+			}
+		}
+
+		positionalArgs = append(positionalArgs, word)
+	}
+
+	if valueFlag != nil {
+		return completeFlagValue(valueFlag, flagValues)
+	}
+
+	if len(positionalArgs) != 0 && command.Annotations[completionAnnotation] == completionCommandLine {
+		return nil, completionDirectiveFiles
+	}
+
+	if strings.HasPrefix(toComplete, "--") && strings.Contains(toComplete, "=") {
+		index := strings.Index(toComplete, "=")
+		flag := completionLookupFlag(command, toComplete[:index])
+		if flag == nil {
+			return nil, completionDirectiveNoFiles
+		}
+
+		values, directive := completeFlagValue(flag, flagValues)
+
+		var completions []string
+		for _, value := range values {
+			completions = append(completions, toComplete[:index+1]+value)
+		}
+
+		return completions, directive
+	}
+
+	if strings.HasPrefix(toComplete, "-") {
+		return completeFlagNames(command), completionDirectiveNoFiles
+	}
+
+	if len(positionalArgs) == 0 && command.HasAvailableSubCommands() {
+		return completeCommandNames(command), completionDirectiveNoFiles
+	}
+
+	if kind, ok := command.Annotations[completionAnnotation]; ok {
+		return completeValues(kind, flagValues)
+	}
+
+	return command.ValidArgs, completionDirectiveNoFiles
+}
+
+func completeCommandNames(command *cobra.Command) []string {
+	var names []string
+
+	for _, subCommand := range command.Commands() {
+		if subCommand.IsAvailableCommand() {
+			names = append(names, subCommand.Name())
+		}
+	}
+
+	if command == rootCmd {
+		for name := range toolboxConfig.Aliases {
+			if !isBuiltInCommand(name) {
+				names = append(names, name)
+			}
+		}
+
+		for _, plugin := range getPlugins() {
+			names = append(names, plugin.Name)
+		}
+	}
+
+	sort.Strings(names)
+
+	// Aliases can have the same names as plugins
+	var uniqueNames []string
+	for i, name := range names {
+		if i == 0 || name != names[i-1] {
+			uniqueNames = append(uniqueNames, name)
+		}
+	}
+
+	return uniqueNames
+}
+
+func completeFlagNames(command *cobra.Command) []string {
+	var names []string
+
+	addFlagNames := func(flag *pflag.Flag) {
+		if flag.Hidden {
+			return
+		}
+
+		names = append(names, "--"+flag.Name)
+		if flag.Shorthand != "" {
+			names = append(names, "-"+flag.Shorthand)
+		}
+	}
+
+	command.LocalFlags().VisitAll(addFlagNames)
+	command.InheritedFlags().VisitAll(addFlagNames)
+
+	sort.Strings(names)
+	return names
+}
+
+func completeFlagValue(flag *pflag.Flag, flagValues map[string]string) ([]string, string) {
+	kinds := flag.Annotations[completionAnnotation]
+	if len(kinds) == 0 {
+		return nil, completionDirectiveNoFiles
+	}
+
+	return completeValues(kinds[0], flagValues)
+}
+
+func completeReleases(distro string) []string {
+	// Without a distribution, or with the host's, this is the host's release
+	_, image, release, err := utils.ResolveContainerAndImageNames("", distro, "", "")
+	if err == nil {
+		distro, _ = utils.GetDistroForImage(image)
+	}
+
+	releases := make(map[string]struct{})
+	if release != "" {
+		releases[release] = struct{}{}
+	}
+
+	if images, err := getImages(); err == nil {
+		for _, image := range images {
+			for _, name := range image.Names {
+				if imageDistro, ok := utils.GetDistroForImage(name); !ok || imageDistro != distro {
+					continue
+				}
+
+				if tag := utils.ImageReferenceGetTag(name); tag != "" && tag != "latest" {
+					releases[tag] = struct{}{}
+				}
+			}
+		}
+	}
+
+	var completions []string
+	for release := range releases {
+		completions = append(completions, release)
+	}
+
+	sort.Strings(completions)
+	return completions
+}
+
+func completeValues(kind string, flagValues map[string]string) ([]string, string) {
+	var completions []string
+
+	switch kind {
+	case completionCommandLine, completionFiles:
+		return nil, completionDirectiveFiles
+	case completionCommands:
+		completions = completeCommandNames(rootCmd)
+	case completionContainers:
+		containers, err := getContainers()
+		if err != nil {
+			logrus.Debugf("Completing container names failed: %s", err)
+			break
+		}
+
+		for _, container := range containers {
+			completions = append(completions, container.Names[0])
+		}
+	case completionDirectories:
+		return nil, completionDirectiveDirectories
+	case completionDistros:
+		completions = utils.GetSupportedDistros()
+	case completionImages:
+		images, err := getImages()
+		if err != nil {
+			logrus.Debugf("Completing image names failed: %s", err)
+			break
+		}
+
+		for _, image := range images {
+			completions = append(completions, image.Names...)
+		}
+	case completionLogFormats:
+		completions = logFormats
+	case completionLogLevels:
+		for _, level := range logrus.AllLevels {
+			completions = append(completions, level.String())
+		}
+	case completionReleases:
+		completions = completeReleases(flagValues["distro"])
+	default:
+		panicMsg := fmt.Sprintf("unknown kind of completion %s", kind)
+		panic(panicMsg)
+	}
+
+	return completions, completionDirectiveNoFiles
+}
+
+func completionFindCommand(command *cobra.Command, name string) *cobra.Command {
+	for _, subCommand := range command.Commands() {
+		if !subCommand.IsAvailableCommand() {
+			continue
+		}
+
+		if subCommand.Name() == name || subCommand.HasAlias(name) {
+			return subCommand
+		}
+	}
+
+	return nil
+}
+
+// completionLookupFlag finds the flag named by word, which is either a long
+// flag, optionally with a value, or one or more short flags, in which case the
+// last one is used.
+func completionLookupFlag(command *cobra.Command, word string) *pflag.Flag {
+	name := strings.SplitN(word, "=", 2)[0]
+	isLongFlag := strings.HasPrefix(name, "--")
+
+	for _, flags := range []*pflag.FlagSet{command.LocalFlags(), command.InheritedFlags()} {
+		var flag *pflag.Flag
+
+		if isLongFlag {
+			flag = flags.Lookup(name[2:])
+		} else if len(name) > 1 {
+			flag = flags.ShorthandLookup(name[len(name)-1:])
+		}
+
+		if flag != nil {
+			return flag
+		}
+	}
+
+	return nil
+}
+
+// markFlagCompletion sets the kind of values that the flag called name is
+// completed with.
+func markFlagCompletion(flags *pflag.FlagSet, name, kind string) {
+	if err := flags.SetAnnotation(name, completionAnnotation, []string{kind}); err != nil {
+		panicMsg := fmt.Sprintf("failed to mark flag %s for completion: %s", name, err)
+		panic(panicMsg)
+	}
+}
+
+const completionScriptBash = `# bash completion for toolbox
+
+# Check for bash
+[ -z "$BASH_VERSION" ] && return
+
+__toolbox() {
+  local cur prev words cword
+  _init_completion -n = || return
+
+  local -a completions
+  mapfile -t completions < <("${words[0]}" __complete "${words[@]:1:cword}" 2>/dev/null)
+  [ "${#completions[@]}" -eq 0 ] && return
+
+  local directive="${completions[-1]}"
+  unset 'completions[-1]'
+
+  # If '=' breaks words, only what follows it in '--option=value' is replaced
+  local prefix=""
+  if [[ "$cur" == -*=* && "$COMP_WORDBREAKS" == *=* ]]; then
+    prefix="${cur%%=*}="
+    cur="${cur#*=}"
+  fi
+
+  case "$directive" in
+    :directories)
+      _filedir -d
+      ;;
+    :files)
+      _filedir
+      ;;
+  esac
+
+  local completion
+  for completion in "${completions[@]}"; do
+    COMPREPLY+=("${completion#"$prefix"}")
+  done
+}
+
+complete -F __toolbox toolbox
+`
+
+const completionScriptFish = `# fish completion for toolbox
+
+function __toolbox_complete
+    set -l args (commandline -opc)
+    set -l current (commandline -ct)
+    set -l toolbox $args[1]
+    set -e args[1]
+
+    set -l completions (command $toolbox __complete $args "$current" 2>/dev/null)
+    test (count $completions) -eq 0; and return
+
+    set -l directive $completions[-1]
+    set -e completions[-1]
+
+    for completion in $completions
+        echo $completion
+    end
+
+    switch $directive
+        case :directories :files
+            set -l prefix (string match -r -- '^-[^=]*=' "$current")
+            set -l path (string replace -r -- '^-[^=]*=' '' "$current")
+            set -l paths
+
+            if test $directive = :directories
+                set paths (__fish_complete_directories "$path")
+            else
+                set paths (__fish_complete_path "$path")
+            end
+
+            for candidate in $paths
+                echo "$prefix$candidate"
+            end
+    end
+end
+
+complete -c toolbox -f -a '(__toolbox_complete)'
+`
+
+const completionScriptZsh = `#compdef toolbox
+
+# zsh completion for toolbox
+
+_toolbox() {
+  local -a completions
+  local directive
+
+  completions=("${(@f)$("${words[1]}" __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+  directive="${completions[-1]}"
+  completions=("${(@)completions[1,-2]}")
+
+  if (( ${#completions} )); then
+    compadd -- "${(@)completions}"
+  fi
+
+  case "$directive" in
+    :directories)
+      [[ "$PREFIX" == -*=* ]] && compset -P '*='
+      _files -/
+      ;;
+    :files)
+      [[ "$PREFIX" == -*=* ]] && compset -P '*='
+      _files
+      ;;
+  esac
+}
+
+if [ "$funcstack[1]" = "_toolbox" ]; then
+  _toolbox "$@"
+else
+  compdef _toolbox toolbox
+fi
+`
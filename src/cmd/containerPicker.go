@@ -0,0 +1,174 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// containerPicker is an interactive list of toolbox containers on a terminal
+// in raw mode, where one is chosen with the arrow keys.
+type containerPicker struct {
+	containers []toolboxContainer
+	lines      int
+	selected   int
+	writer     io.Writer
+}
+
+// canPickContainer tells whether the user can be asked to choose a toolbox
+// container interactively.
+func canPickContainer() bool {
+	if rootFlags.assumeYes {
+		return false
+	}
+
+	stdinFd := os.Stdin.Fd()
+	stdinFdInt := int(stdinFd)
+
+	stderrFd := os.Stderr.Fd()
+	stderrFdInt := int(stderrFd)
+
+	return terminal.IsTerminal(stdinFdInt) && terminal.IsTerminal(stderrFdInt)
+}
+
+// pickContainer asks the user to choose one of the containers. An empty
+// string is returned if the user cancelled.
+func pickContainer(containers []toolboxContainer) (string, error) {
+	if len(containers) == 0 {
+		panic("no containers to pick from")
+	}
+
+	stdinFd := os.Stdin.Fd()
+	stdinFdInt := int(stdinFd)
+
+	oldState, err := terminal.MakeRaw(stdinFdInt)
+	if err != nil {
+		return "", fmt.Errorf("failed to set up the terminal: %w", err)
+	}
+
+	defer terminal.Restore(stdinFdInt, oldState)
+
+	picker := &containerPicker{
+		containers: containers,
+		writer:     os.Stderr,
+	}
+
+	picker.draw()
+
+	buffer := make([]byte, 8)
+
+	for {
+		n, err := os.Stdin.Read(buffer)
+		if err != nil {
+			picker.clear()
+			return "", fmt.Errorf("failed to read from the terminal: %w", err)
+		}
+
+		done, cancelled := picker.handleKey(buffer[:n])
+		if cancelled {
+			picker.clear()
+			return "", nil
+		}
+
+		if done {
+			picker.clear()
+
+			container := containers[picker.selected].Names[0]
+			return container, nil
+		}
+
+		picker.draw()
+	}
+}
+
+// clear erases the list, so that the terminal looks as if it was never shown.
+func (picker *containerPicker) clear() {
+	if picker.lines > 0 {
+		fmt.Fprintf(picker.writer, "\033[%dA\r\033[J", picker.lines)
+	}
+
+	picker.lines = 0
+}
+
+// draw shows the list, replacing the previous one. Lines end with "\r\n"
+// because the terminal is in raw mode.
+func (picker *containerPicker) draw() {
+	var buffer bytes.Buffer
+
+	writer := tabwriter.NewWriter(&buffer, 0, 0, 2, ' ', 0)
+
+	for i, container := range picker.containers {
+		marker := " "
+		if i == picker.selected {
+			marker = ">"
+		}
+
+		fmt.Fprintf(writer, "%s %s\t%s\t%s\n", marker, container.Names[0], container.Image, container.Status)
+	}
+
+	writer.Flush()
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+
+	picker.clear()
+
+	fmt.Fprintf(picker.writer, "Choose a toolbox container:\r\n")
+
+	for i, line := range lines {
+		if i == picker.selected {
+			fmt.Fprintf(picker.writer, "\033[7m%s\033[0m\r\n", line)
+		} else {
+			fmt.Fprintf(picker.writer, "%s\r\n", line)
+		}
+	}
+
+	fmt.Fprintf(picker.writer, "Use the arrow keys to move, Enter to choose and q to cancel.\r\n")
+
+	picker.lines = len(lines) + 2
+}
+
+// handleKey moves the selection or ends the interaction, depending on the
+// key that was pressed.
+func (picker *containerPicker) handleKey(key []byte) (bool, bool) {
+	switch string(key) {
+	case "\033[A", "\033OA", "k":
+		if picker.selected > 0 {
+			picker.selected--
+		}
+	case "\033[B", "\033OB", "j":
+		if picker.selected < len(picker.containers)-1 {
+			picker.selected++
+		}
+	case "\033[H", "\033OH", "g":
+		picker.selected = 0
+	case "\033[F", "\033OF", "G":
+		picker.selected = len(picker.containers) - 1
+	case "\r", "\n":
+		return true, false
+	case "\033", "\003", "\004", "q":
+		return false, true
+	}
+
+	return false, false
+}
@@ -20,14 +20,19 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/user"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
+	"github.com/containers/toolbox/pkg/config"
 	"github.com/containers/toolbox/pkg/podman"
 	"github.com/containers/toolbox/pkg/shell"
+	"github.com/containers/toolbox/pkg/skopeo"
 	"github.com/containers/toolbox/pkg/utils"
+	"github.com/docker/go-units"
 	"github.com/godbus/dbus/v5"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -40,12 +45,23 @@ const (
 	alphanum = alpha + num
 )
 
+const (
+	// toolboxDigestLabel records the digest that a toolbox container was
+	// pinned to with 'create --pin' or a lockfile
+	toolboxDigestLabel = "com.github.containers.toolbox.digest"
+)
+
 var (
 	createFlags struct {
-		container string
-		distro    string
-		image     string
-		release   string
+		container   string
+		distro      string
+		fromArchive string
+		image       string
+		lockfile    string
+		pin         bool
+		quiet       bool
+		release     string
+		verify      bool
 	}
 
 	createToolboxShMounts = []struct {
@@ -55,6 +71,18 @@ var (
 		{"/etc/profile.d/toolbox.sh", "/etc/profile.d/toolbox.sh"},
 		{"/etc/profile.d/toolbox.sh", "/usr/share/profile.d/toolbox.sh"},
 	}
+
+	// createImageNameInvalidChars matches the characters that can't be
+	// used in the name of an image
+	createImageNameInvalidChars = regexp.MustCompile("[^a-z0-9._-]+")
+
+	// createImageNameSeparators matches runs of separators that aren't
+	// allowed in the name of an image
+	createImageNameSeparators = regexp.MustCompile("[._-]{2,}")
+
+	// imageSizeTimeout limits how long the prompt to download an image
+	// waits for its size
+	imageSizeTimeout = 10 * time.Second
 )
 
 var createCmd = &cobra.Command{
@@ -78,18 +106,50 @@ func init() {
 		"",
 		"Create a toolbox container for a different operating system distribution than the host")
 
+	flags.StringVar(&createFlags.fromArchive,
+		"from-archive",
+		"",
+		"Create the toolbox container from an image in a docker-archive or oci-archive file")
+
 	flags.StringVarP(&createFlags.image,
 		"image",
 		"i",
 		"",
 		"Change the name of the base image used to create the toolbox container")
 
+	flags.StringVar(&createFlags.lockfile,
+		"lockfile",
+		"",
+		"Use FILE to look up and record the digests that images are pinned to")
+
+	flags.BoolVar(&createFlags.pin,
+		"pin",
+		false,
+		"Pin the toolbox container to the digest that the image currently resolves to")
+
+	flags.BoolVarP(&createFlags.quiet,
+		"quiet",
+		"q",
+		false,
+		"Don't show the progress of pulling the image and creating the toolbox container")
+
 	flags.StringVarP(&createFlags.release,
 		"release",
 		"r",
 		"",
 		"Create a toolbox container for a different operating system release than the host")
 
+	flags.BoolVar(&createFlags.verify,
+		"verify-signatures",
+		false,
+		"Refuse to create the toolbox container unless the image's signature is valid")
+
+	markFlagCompletion(flags, "distro", completionDistros)
+	markFlagCompletion(flags, "from-archive", completionFiles)
+	markFlagCompletion(flags, "image", completionImages)
+	markFlagCompletion(flags, "lockfile", completionFiles)
+	markFlagCompletion(flags, "release", completionReleases)
+
 	createCmd.SetHelpFunc(createHelp)
 	rootCmd.AddCommand(createCmd)
 }
@@ -115,6 +175,29 @@ func create(cmd *cobra.Command, args []string) error {
 		return errors.New("options --image and --release cannot be used together")
 	}
 
+	if cmd.Flag("from-archive").Changed {
+		// Pinning and verifying an image need its registry, so they
+		// would defeat the purpose of loading it from an archive. An
+		// image from an archive can't be verified, so it's refused if
+		// the configuration requires verification, like images that
+		// were built locally.
+		for _, option := range []string{"distro", "image", "lockfile", "pin", "release", "verify-signatures"} {
+			if cmd.Flag(option).Changed {
+				return fmt.Errorf("options --from-archive and --%s cannot be used together", option)
+			}
+		}
+
+		if toolboxConfig.Signatures.Verify {
+			var builder strings.Builder
+			fmt.Fprintf(&builder, "option --from-archive cannot be used when signatures are verified\n")
+			fmt.Fprintf(&builder, "Images from archives can't be verified, but 'verify' is set in the\n")
+			fmt.Fprintf(&builder, "[signatures] table of toolbox.conf(5).")
+
+			errMsg := builder.String()
+			return errors.New(errMsg)
+		}
+	}
+
 	var container string
 	var containerArg string
 
@@ -148,22 +231,55 @@ func create(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	image := createFlags.image
+
+	if createFlags.fromArchive != "" {
+		var err error
+		image, err = loadImageFromArchive(createFlags.fromArchive)
+		if err != nil {
+			return err
+		}
+	}
+
 	container, image, release, err := utils.ResolveContainerAndImageNames(container,
 		createFlags.distro,
-		createFlags.image,
+		image,
 		release)
 	if err != nil {
 		return err
 	}
 
-	if err := createContainer(container, image, release, true); err != nil {
+	lockfile := createFlags.lockfile
+	verify := createFlags.verify
+
+	if createFlags.fromArchive != "" {
+		logrus.Debug("Not looking for a lockfile for an image loaded from an archive")
+	} else {
+		if lockfile == "" {
+			lockfile = config.FindLockfile(workingDirectory)
+		}
+
+		verify = verify || toolboxConfig.Signatures.Verify
+	}
+
+	if _, err := createContainer(container,
+		image,
+		release,
+		lockfile,
+		createFlags.pin,
+		verify,
+		true,
+		createFlags.quiet); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func createContainer(container, image, release string, showCommandToEnter bool) error {
+// createContainer creates a toolbox container, and reports whether it was
+// created. It's not an error if it wasn't, because the user declined to
+// download the image.
+func createContainer(container, image, release, lockfile string, pin, verify, showCommandToEnter, quiet bool) (bool, error) {
 	if container == "" {
 		panic("container not specified")
 	}
@@ -187,20 +303,46 @@ func createContainer(container, image, release string, showCommandToEnter bool)
 		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
 
 		errMsg := builder.String()
-		return errors.New(errMsg)
+		return false, errors.New(errMsg)
+	}
+
+	var digest string
+	var digestLabel []string
+
+	if pin || lockfile != "" {
+		var err error
+		image, digest, err = pinImage(image, release, lockfile, pin)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if digest != "" {
+		digestLabelArg := toolboxDigestLabel + "=" + digest
+		digestLabel = []string{"--label", digestLabelArg}
+	}
+
+	var signaturePolicy string
+
+	if verify {
+		var err error
+		image, signaturePolicy, err = verifyImage(image, release)
+		if err != nil {
+			return false, err
+		}
 	}
 
-	pulled, err := pullImage(image, release)
+	pulled, err := pullImage(image, release, signaturePolicy, quiet)
 	if err != nil {
-		return err
+		return false, err
 	}
 	if !pulled {
-		return nil
+		return false, nil
 	}
 
 	imageFull, err := getFullyQualifiedImageFromRepoTags(image)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	toolboxPath := os.Getenv("TOOLBOX_PATH")
@@ -213,7 +355,7 @@ func createContainer(container, image, release string, showCommandToEnter bool)
 	if currentUser.Uid == "0" {
 		runtimeDirectory, err = utils.GetRuntimeDirectory(currentUser)
 		if err != nil {
-			return err
+			return false, err
 		}
 	} else {
 		xdgRuntimeDir := os.Getenv("XDG_RUNTIME_DIR")
@@ -252,14 +394,14 @@ func createContainer(container, image, release string, showCommandToEnter bool)
 
 	dbusSystemSocket, err := getDBusSystemSocket()
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	dbusSystemSocketMountArg := dbusSystemSocket + ":" + dbusSystemSocket
 
 	homeDirEvaled, err := filepath.EvalSymlinks(currentUser.HomeDir)
 	if err != nil {
-		return fmt.Errorf("failed to canonicalize %s", currentUser.HomeDir)
+		return false, fmt.Errorf("failed to canonicalize %s", currentUser.HomeDir)
 	}
 
 	logrus.Debugf("%s canonicalized to %s", currentUser.HomeDir, homeDirEvaled)
@@ -268,7 +410,7 @@ func createContainer(container, image, release string, showCommandToEnter bool)
 	bootMountFlags := "ro"
 	isBootReadWrite, err := isPathReadWrite("/boot")
 	if err != nil {
-		return err
+		return false, err
 	}
 	if isBootReadWrite {
 		bootMountFlags = "rw"
@@ -279,7 +421,7 @@ func createContainer(container, image, release string, showCommandToEnter bool)
 	usrMountFlags := "ro"
 	isUsrReadWrite, err := isPathReadWrite("/usr")
 	if err != nil {
-		return err
+		return false, err
 	}
 	if isUsrReadWrite {
 		usrMountFlags = "rw"
@@ -369,11 +511,37 @@ func createContainer(container, image, release string, showCommandToEnter bool)
 		slashHomeLink = []string{"--home-link"}
 	}
 
+	var groupsArg []string
+
+	supplementaryGroups := getSupplementaryGroups()
+	if len(supplementaryGroups) != 0 {
+		groupsArg = []string{"--groups", strings.Join(supplementaryGroups, ",")}
+	}
+
+	// Rootless Podman doesn't map the host's supplementary groups into the
+	// user namespace, so the mirrored groups only grant access to devices
+	// if the container keeps the groups of the user who created it
+	var keepGroups []string
+
+	if len(supplementaryGroups) != 0 && currentUser.Uid != "0" {
+		logrus.Debug("Checking if 'podman create' supports '--group-add keep-groups'")
+
+		if podman.CheckVersion("3.2.0") {
+			ociRuntime, err := podman.GetOCIRuntime()
+			if err != nil {
+				logrus.Debugf("Getting the OCI runtime failed: %s", err)
+			} else if ociRuntime == "crun" {
+				logrus.Debug("'podman create' supports '--group-add keep-groups'")
+				keepGroups = []string{"--group-add", "keep-groups"}
+			}
+		}
+	}
+
 	logLevelString := podman.LogLevel.String()
 
 	userShell := os.Getenv("SHELL")
 	if userShell == "" {
-		return errors.New("failed to get the current user's default shell")
+		return false, errors.New("failed to get the current user's default shell")
 	}
 
 	entryPoint := []string{
@@ -387,6 +555,7 @@ func createContainer(container, image, release string, showCommandToEnter bool)
 		"--monitor-host",
 	}
 
+	entryPoint = append(entryPoint, groupsArg...)
 	entryPoint = append(entryPoint, slashHomeLink...)
 	entryPoint = append(entryPoint, mediaLink...)
 	entryPoint = append(entryPoint, mntLink...)
@@ -407,7 +576,9 @@ func createContainer(container, image, release string, showCommandToEnter bool)
 		"--label", "com.github.debarshiray.toolbox=true",
 	}...)
 
+	createArgs = append(createArgs, digestLabel...)
 	createArgs = append(createArgs, devPtsMount...)
+	createArgs = append(createArgs, keepGroups...)
 
 	createArgs = append(createArgs, []string{
 		"--name", container,
@@ -459,7 +630,7 @@ func createContainer(container, image, release string, showCommandToEnter bool)
 
 	stdoutFd := os.Stdout.Fd()
 	stdoutFdInt := int(stdoutFd)
-	if logLevel := logrus.GetLevel(); logLevel < logrus.DebugLevel && terminal.IsTerminal(stdoutFdInt) {
+	if logLevel := logrus.GetLevel(); logLevel < logrus.DebugLevel && !quiet && terminal.IsTerminal(stdoutFdInt) {
 		s.Prefix = fmt.Sprintf("Creating container %s: ", container)
 		s.Writer = os.Stdout
 		s.Start()
@@ -467,18 +638,18 @@ func createContainer(container, image, release string, showCommandToEnter bool)
 	}
 
 	if err := shell.Run("podman", nil, nil, nil, createArgs...); err != nil {
-		return fmt.Errorf("failed to create container %s", container)
+		return false, fmt.Errorf("failed to create container %s", container)
 	}
 
 	// The spinner must be stopped before showing the 'enter' hit below.
 	s.Stop()
 
-	if showCommandToEnter {
+	if showCommandToEnter && !quiet {
 		fmt.Printf("Created container: %s\n", container)
 		fmt.Printf("Enter with: %s\n", enterCommand)
 	}
 
-	return nil
+	return true, nil
 }
 
 func createHelp(cmd *cobra.Command, args []string) {
@@ -569,7 +740,7 @@ func getFullyQualifiedImageFromRepoTags(image string) (string, error) {
 		for _, repoTag := range repoTags {
 			repoTagString := repoTag.(string)
 			tag := utils.ImageReferenceGetTag(repoTagString)
-			if tag != "latest" {
+			if tag != utils.ImageReferenceDefaultTag {
 				imageFull = repoTagString
 				break
 			}
@@ -585,6 +756,30 @@ func getFullyQualifiedImageFromRepoTags(image string) (string, error) {
 	return imageFull, nil
 }
 
+// getImageNameFromArchive turns the file name of an image archive into a
+// valid image name, like foo for /path/to/Foo.tar.gz.
+func getImageNameFromArchive(archive string) string {
+	name := filepath.Base(archive)
+
+	for _, extension := range []string{".gz", ".xz", ".zst", ".tar", ".ociarchive"} {
+		name = strings.TrimSuffix(name, extension)
+	}
+
+	return sanitizeImageName(name, "toolbox-archive")
+}
+
+func getImageSizeFromRegistry(imageFull string) (string, error) {
+	logrus.Debugf("Getting the size of image %s", imageFull)
+
+	imageSize, err := skopeo.GetSize(imageFull, imageSizeTimeout)
+	if err != nil {
+		return "", err
+	}
+
+	imageSizeHuman := units.HumanSize(float64(imageSize))
+	return imageSizeHuman, nil
+}
+
 func getServiceSocket(serviceName string, unitName string) (string, error) {
 	logrus.Debugf("Resolving path to the %s socket", serviceName)
 
@@ -644,6 +839,47 @@ func getServiceSocket(serviceName string, unitName string) (string, error) {
 	return "", fmt.Errorf("failed to find a SOCK_STREAM socket for %s", unitName)
 }
 
+// getSupplementaryGroups returns the current user's supplementary groups as
+// NAME:GID, leaving out those that the user opted out of.
+func getSupplementaryGroups() []string {
+	logrus.Debugf("Looking up supplementary groups of user %s", currentUser.Username)
+
+	gids, err := currentUser.GroupIds()
+	if err != nil {
+		logrus.Debugf("Looking up supplementary groups of user %s failed: %s", currentUser.Username, err)
+		return nil
+	}
+
+	skipGroups := make(map[string]bool)
+	for _, group := range toolboxConfig.Create.SkipGroups {
+		skipGroups[group] = true
+	}
+
+	var supplementaryGroups []string
+
+	for _, gid := range gids {
+		if gid == currentUser.Gid {
+			continue
+		}
+
+		group, err := user.LookupGroupId(gid)
+		if err != nil {
+			logrus.Debugf("Looking up group with GID %s failed: %s", gid, err)
+			continue
+		}
+
+		if skipGroups[group.Name] {
+			logrus.Debugf("Skipping supplementary group %s", group.Name)
+			continue
+		}
+
+		logrus.Debugf("Found supplementary group %s with GID %s", group.Name, gid)
+		supplementaryGroups = append(supplementaryGroups, group.Name+":"+gid)
+	}
+
+	return supplementaryGroups
+}
+
 func isPathReadWrite(path string) (bool, error) {
 	logrus.Debugf("Checking if %s is mounted read-only or read-write", path)
 
@@ -668,8 +904,130 @@ func isPathReadWrite(path string) (bool, error) {
 	return false, nil
 }
 
-func pullImage(image, release string) (bool, error) {
-	if _, err := utils.ImageReferenceCanBeID(image); err == nil {
+// loadImageFromArchive loads an image from a local archive, so that a toolbox
+// container can be created without network access. Images without a name are
+// named after the archive.
+func loadImageFromArchive(archive string) (string, error) {
+	logrus.Debugf("Loading image from archive %s", archive)
+
+	if !utils.PathExists(archive) {
+		return "", fmt.Errorf("archive %s not found", archive)
+	}
+
+	images, err := podman.Load(archive)
+	if err != nil {
+		logrus.Debugf("Loading image from archive %s failed: %s", archive, err)
+		return "", fmt.Errorf("failed to load image from archive %s", archive)
+	}
+
+	image := images[0]
+	logrus.Debugf("Loaded image %s from archive %s", image, archive)
+
+	if strings.HasPrefix(image, "sha256:") {
+		imageName := "localhost/" + getImageNameFromArchive(archive)
+
+		logrus.Debugf("Naming image %s as %s", image, imageName)
+
+		if err := podman.Tag(image, imageName); err != nil {
+			return "", fmt.Errorf("failed to name image %s from archive %s", image, archive)
+		}
+
+		image = imageName
+	}
+
+	if _, err := podman.IsToolboxImage(image); err != nil {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "image %s in archive %s is not a toolbox image\n", image, archive)
+		fmt.Fprintf(&builder, "Toolbox images must have the label com.github.containers.toolbox=true.")
+
+		errMsg := builder.String()
+		return "", errors.New(errMsg)
+	}
+
+	return image, nil
+}
+
+// pinImage resolves image to a digest, either from the lockfile or, if pin is
+// set, from its registry. In the latter case, the digest is recorded in the
+// lockfile, if any. The image is returned as a reference with the digest,
+// along with the digest. Without pin, images that aren't in the lockfile are
+// returned as they are.
+func pinImage(image, release, lockfilePath string, pin bool) (string, string, error) {
+	reference, err := utils.ParseImageReference(image)
+	if err != nil {
+		return "", "", err
+	}
+
+	if reference.Digest != "" {
+		return image, reference.Digest, nil
+	}
+
+	imageFull := image
+	if reference.Domain == "" {
+		imageFull, err = utils.GetFullyQualifiedImageFromDistros(image, release)
+	}
+
+	if err != nil || utils.ImageReferenceGetDomain(imageFull) == "localhost" {
+		if !pin {
+			return image, "", nil
+		}
+
+		return "", "", fmt.Errorf("image %s can't be pinned because it's not from a registry", image)
+	}
+
+	referenceFull, err := utils.ParseImageReference(imageFull)
+	if err != nil {
+		return "", "", err
+	}
+
+	lockedImage := referenceFull.Name() + ":" + referenceFull.TagOrDefault()
+
+	var lockfile *config.Lockfile
+
+	if lockfilePath != "" {
+		lockfile, err = config.LoadLockfile(lockfilePath)
+		if err != nil {
+			return "", "", err
+		}
+
+		if digest := lockfile.Digest(lockedImage); digest != "" {
+			logrus.Debugf("Image %s is locked to %s in %s", lockedImage, digest, lockfilePath)
+
+			imagePinned := referenceFull.Name() + "@" + digest
+			return imagePinned, digest, nil
+		}
+	}
+
+	if !pin {
+		return image, "", nil
+	}
+
+	logrus.Debugf("Resolving image %s to a digest", imageFull)
+
+	remoteImage, err := skopeo.Inspect(imageFull, 0)
+	if err != nil {
+		logrus.Debugf("Resolving image %s to a digest failed: %s", imageFull, err)
+		return "", "", fmt.Errorf("failed to resolve image %s to a digest", imageFull)
+	}
+
+	digest := remoteImage.Digest
+	logrus.Debugf("Image %s resolved to %s", imageFull, digest)
+
+	if lockfile != nil {
+		lockfile.SetDigest(lockedImage, digest)
+		if err := lockfile.Save(); err != nil {
+			return "", "", err
+		}
+
+		fmt.Printf("Pinned %s to %s in %s\n", lockedImage, digest, lockfile.Path())
+	}
+
+	imagePinned := referenceFull.Name() + "@" + digest
+	return imagePinned, digest, nil
+}
+
+func pullImage(image, release, signaturePolicy string, quiet bool) (bool, error) {
+	if utils.ImageReferenceCanBeID(image) {
 		logrus.Debugf("Looking for image %s", image)
 
 		if _, err := podman.ImageExists(image); err == nil {
@@ -703,6 +1061,21 @@ func pullImage(image, release string) (bool, error) {
 	logrus.Debugf("Looking for image %s", imageFull)
 
 	if _, err := podman.ImageExists(imageFull); err == nil {
+		if signaturePolicy == "" {
+			return true, nil
+		}
+
+		// Pulling an image that's already present only fetches its
+		// manifest and signatures, which is enough to verify it
+		logrus.Debugf("Verifying image %s with signature policy %s", imageFull, signaturePolicy)
+
+		if err := podman.Pull(imageFull, signaturePolicy, nil); err != nil {
+			return false, fmt.Errorf("image %s was rejected by signature policy %s: %w",
+				imageFull,
+				signaturePolicy,
+				err)
+		}
+
 		return true, nil
 	}
 
@@ -721,10 +1094,20 @@ func pullImage(image, release string) (bool, error) {
 	}
 
 	if promptForDownload {
-		fmt.Println("Image required to create toolbox container.")
+		// The prompt goes to the standard error, so that it doesn't
+		// end up in the output of 'run'
+		fmt.Fprintln(os.Stderr, "Image required to create toolbox container.")
 
-		prompt := fmt.Sprintf("Download %s (500MB)? [y/N]:", imageFull)
-		shouldPullImage = utils.AskForConfirmation(prompt)
+		var prompt string
+
+		if imageSize, err := getImageSizeFromRegistry(imageFull); err != nil {
+			logrus.Debugf("Getting the size of image %s failed: %s", imageFull, err)
+			prompt = fmt.Sprintf("Download %s? [y/N]:", imageFull)
+		} else {
+			prompt = fmt.Sprintf("Download %s (%s)? [y/N]:", imageFull, imageSize)
+		}
+
+		shouldPullImage = utils.AskForConfirmationOn(os.Stderr, prompt)
 	}
 
 	if !shouldPullImage {
@@ -733,23 +1116,47 @@ func pullImage(image, release string) (bool, error) {
 
 	logrus.Debugf("Pulling image %s", imageFull)
 
-	stdoutFd := os.Stdout.Fd()
-	stdoutFdInt := int(stdoutFd)
-	if logLevel := logrus.GetLevel(); logLevel < logrus.DebugLevel && terminal.IsTerminal(stdoutFdInt) {
-		s := spinner.New(spinner.CharSets[9], 500*time.Millisecond)
-		s.Prefix = fmt.Sprintf("Pulling %s: ", imageFull)
-		s.Writer = os.Stdout
-		s.Start()
-		defer s.Stop()
+	var progress func(podman.PullProgress)
+
+	if logLevel := logrus.GetLevel(); logLevel < logrus.DebugLevel && !quiet {
+		stdoutFd := os.Stdout.Fd()
+		stdoutFdInt := int(stdoutFd)
+
+		bar := newPullProgressBar(imageFull, os.Stdout, terminal.IsTerminal(stdoutFdInt))
+		defer bar.finish()
+
+		progress = bar.update
 	}
 
-	if err := podman.Pull(imageFull); err != nil {
+	if err := podman.Pull(imageFull, signaturePolicy, progress); err != nil {
+		if signaturePolicy != "" {
+			return false, fmt.Errorf("failed to pull image %s with signature policy %s: %w",
+				imageFull,
+				signaturePolicy,
+				err)
+		}
+
 		return false, fmt.Errorf("failed to pull image %s", imageFull)
 	}
 
 	return true, nil
 }
 
+// sanitizeImageName turns an arbitrary string into a valid image name without
+// a tag. If nothing is left, fallback is used.
+func sanitizeImageName(name, fallback string) string {
+	name = strings.ToLower(name)
+	name = createImageNameInvalidChars.ReplaceAllString(name, "-")
+	name = createImageNameSeparators.ReplaceAllString(name, "-")
+	name = strings.Trim(name, "-._")
+
+	if name == "" {
+		name = fallback
+	}
+
+	return name
+}
+
 // systemdNeedsEscape checks whether a byte in a potential dbus ObjectPath needs to be escaped
 func systemdNeedsEscape(i int, b byte) bool {
 	// Escape everything that is not a-z-A-Z-0-9
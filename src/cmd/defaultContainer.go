@@ -0,0 +1,157 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containers/toolbox/pkg/config"
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// getContainerForDirectory returns the toolbox container selected for dir by
+// a marker file in it or its parents, or by the [directories] table in the
+// configuration, along with where it was selected. An empty string is
+// returned if there's none.
+func getContainerForDirectory(dir string) (string, string, error) {
+	container, markerPath, err := config.FindMarker(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	source := markerPath
+
+	if container == "" && toolboxConfig != nil {
+		var mappedPath string
+		container, mappedPath = toolboxConfig.ContainerForDirectory(dir, currentUser.HomeDir)
+		source = fmt.Sprintf("the configuration for %s", mappedPath)
+	}
+
+	if container == "" {
+		return "", "", nil
+	}
+
+	if !utils.IsContainerNameValid(container) {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "invalid container name %s in %s\n", container, source)
+		fmt.Fprintf(&builder, "Container names must match '%s'", utils.ContainerNameRegexp)
+
+		errMsg := builder.String()
+		return "", "", errors.New(errMsg)
+	}
+
+	return container, source, nil
+}
+
+// getSavedDefaultContainer returns the toolbox container that the user chose
+// as the default instead of the one for the host, if any.
+func getSavedDefaultContainer() string {
+	defaultContainerPath, err := getSavedDefaultContainerPath()
+	if err != nil {
+		logrus.Debugf("Reading the default container: %s", err)
+		return ""
+	}
+
+	data, err := ioutil.ReadFile(defaultContainerPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logrus.Debugf("Reading the default container: %s", err)
+		}
+
+		return ""
+	}
+
+	container := strings.TrimSpace(string(data))
+	return container
+}
+
+func getSavedDefaultContainerPath() (string, error) {
+	stateDirectory, err := utils.GetStateDirectory()
+	if err != nil {
+		return "", err
+	}
+
+	defaultContainerPath := stateDirectory + "/default-container"
+	return defaultContainerPath, nil
+}
+
+// resolveDefaultContainer returns the toolbox container to use when none was
+// specified. The one selected for the current directory comes first, followed
+// by the one chosen by the user as the default, if useSaved is set, and finally
+// container, which is the default for the host.
+func resolveDefaultContainer(container string, useSaved bool) (string, error) {
+	dirContainer, source, err := getContainerForDirectory(workingDirectory)
+	if err != nil {
+		return "", err
+	}
+
+	if dirContainer != "" {
+		logrus.Debugf("Using container %s selected by %s", dirContainer, source)
+		return dirContainer, nil
+	}
+
+	if useSaved {
+		container = useSavedDefaultContainer(container)
+	}
+
+	return container, nil
+}
+
+// saveDefaultContainer makes container the one used when no container is
+// specified, instead of the one for the host.
+func saveDefaultContainer(container string) error {
+	defaultContainerPath, err := getSavedDefaultContainerPath()
+	if err != nil {
+		return err
+	}
+
+	stateDirectory := filepath.Dir(defaultContainerPath)
+	if err := os.MkdirAll(stateDirectory, 0700); err != nil {
+		return fmt.Errorf("failed to create state directory %s: %w", stateDirectory, err)
+	}
+
+	data := []byte(container + "\n")
+	if err := ioutil.WriteFile(defaultContainerPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", defaultContainerPath, err)
+	}
+
+	return nil
+}
+
+// useSavedDefaultContainer returns the default container chosen by the user,
+// if it still exists, or container otherwise.
+func useSavedDefaultContainer(container string) string {
+	savedDefault := getSavedDefaultContainer()
+	if savedDefault == "" || savedDefault == container {
+		return container
+	}
+
+	if _, err := podman.ContainerExists(savedDefault); err != nil {
+		logrus.Debugf("Default container %s not found", savedDefault)
+		return container
+	}
+
+	logrus.Debugf("Using default container %s instead of %s", savedDefault, container)
+	return savedDefault
+}
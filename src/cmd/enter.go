@@ -28,39 +28,84 @@ import (
 
 var (
 	enterFlags struct {
+		cleanEnv  bool
 		container string
+		create    bool
 		distro    string
+		env       []string
+		envFile   []string
 		release   string
+		root      bool
+		workDir   string
 	}
 )
 
 var enterCmd = &cobra.Command{
-	Use:   "enter",
-	Short: "Enter a toolbox container for interactive use",
-	RunE:  enter,
+	Use:         "enter",
+	Short:       "Enter a toolbox container for interactive use",
+	RunE:        enter,
+	Annotations: map[string]string{completionAnnotation: completionContainers},
 }
 
 func init() {
 	flags := enterCmd.Flags()
 
+	flags.BoolVar(&enterFlags.cleanEnv,
+		"clean-env",
+		false,
+		"Enter the toolbox container with a minimal environment instead of the host's")
+
 	flags.StringVarP(&enterFlags.container,
 		"container",
 		"c",
 		"",
 		"Enter a toolbox container with the given name")
 
+	flags.BoolVar(&enterFlags.create,
+		"create",
+		false,
+		"Create the toolbox container if it doesn't exist")
+
 	flags.StringVarP(&enterFlags.distro,
 		"distro",
 		"d",
 		"",
 		"Enter a toolbox container for a different operating system distribution than the host")
 
+	flags.StringArrayVarP(&enterFlags.env,
+		"env",
+		"e",
+		nil,
+		"Set an environment variable inside the toolbox container, as KEY=VALUE or KEY")
+
+	flags.StringArrayVar(&enterFlags.envFile,
+		"env-file",
+		nil,
+		"Read environment variables to set inside the toolbox container from a file")
+
 	flags.StringVarP(&enterFlags.release,
 		"release",
 		"r",
 		"",
 		"Enter a toolbox container for a different operating system release than the host")
 
+	flags.BoolVar(&enterFlags.root,
+		"root",
+		false,
+		"Enter the toolbox container as root")
+
+	flags.StringVarP(&enterFlags.workDir,
+		"workdir",
+		"w",
+		"",
+		"Start in a different working directory inside the toolbox container")
+
+	markFlagCompletion(flags, "container", completionContainers)
+	markFlagCompletion(flags, "distro", completionDistros)
+	markFlagCompletion(flags, "env-file", completionFiles)
+	markFlagCompletion(flags, "release", completionReleases)
+	markFlagCompletion(flags, "workdir", completionDirectories)
+
 	enterCmd.SetHelpFunc(enterHelp)
 	rootCmd.AddCommand(enterCmd)
 }
@@ -104,6 +149,10 @@ func enter(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if enterFlags.distro != "" {
+		nonDefaultContainer = true
+	}
+
 	var release string
 	if enterFlags.release != "" {
 		nonDefaultContainer = true
@@ -116,6 +165,15 @@ func enter(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	options, err := newExecOptions(enterFlags.env,
+		enterFlags.envFile,
+		enterFlags.workDir,
+		enterFlags.cleanEnv,
+		enterFlags.root)
+	if err != nil {
+		return err
+	}
+
 	container, image, release, err := utils.ResolveContainerAndImageNames(container, enterFlags.distro, "", release)
 	if err != nil {
 		return err
@@ -149,6 +207,8 @@ func enter(cmd *cobra.Command, args []string) error {
 		image,
 		release,
 		command,
+		options,
+		enterFlags.create || toolboxConfig.Create.OnDemand,
 		emitEscapeSequence,
 		true,
 		false); err != nil {
@@ -26,9 +26,10 @@ import (
 )
 
 var helpCmd = &cobra.Command{
-	Use:   "help",
-	Short: "Display help information about Toolbox",
-	RunE:  help,
+	Use:         "help",
+	Short:       "Display help information about Toolbox",
+	RunE:        help,
+	Annotations: map[string]string{completionAnnotation: completionCommands},
 }
 
 func init() {
@@ -88,7 +89,12 @@ func helpShowManual(args []string) error {
 		manual = "toolbox-" + args[0]
 	}
 
-	if err := utils.ShowManual(manual); err != nil {
+	var pluginsHelp string
+	if manual == "toolbox" {
+		pluginsHelp = getPluginsHelp()
+	}
+
+	if err := utils.ShowManualFollowedBy(manual, pluginsHelp); err != nil {
 		return err
 	}
 
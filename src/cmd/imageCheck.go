@@ -0,0 +1,338 @@
+/*
+ * Copyright © 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/skopeo"
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	imageStatusLocal    = "local"
+	imageStatusOutdated = "outdated"
+	imageStatusUnknown  = "unknown"
+	imageStatusUpToDate = "up to date"
+
+	containerStatusImageOutdated = "image outdated"
+	containerStatusImageUpdated  = "newer image available locally"
+)
+
+var (
+	// imageCheckDefaultInterval is the shortest time between two checks
+	// for the hint shown by 'enter', unless configured otherwise
+	imageCheckDefaultInterval = 24 * time.Hour
+
+	// imageCheckHintTimeout limits how long 'enter' waits for the registry
+	imageCheckHintTimeout = 5 * time.Second
+)
+
+var imageCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check if toolbox images and containers are outdated",
+	RunE:  imageCheck,
+}
+
+func init() {
+	imageCheckCmd.SetHelpFunc(imageCheckHelp)
+	imageCmd.AddCommand(imageCheckCmd)
+}
+
+func imageCheck(cmd *cobra.Command, args []string) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	images, err := getImages()
+	if err != nil {
+		return err
+	}
+
+	containers, err := getContainers()
+	if err != nil {
+		return err
+	}
+
+	if len(images) == 0 {
+		fmt.Println("No toolbox images found.")
+		return nil
+	}
+
+	imageStatuses := make(map[string]string)
+	imageNameStatuses := make(map[string]string)
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "%s\t%s\t%s\n", "IMAGE ID", "IMAGE NAME", "STATUS")
+
+	var outdatedImages bool
+
+	for _, image := range images {
+		for _, name := range image.Names {
+			status, err := checkImage(name, image, 0)
+			if err != nil {
+				logrus.Debugf("Checking image %s failed: %s", name, err)
+			}
+
+			imageNameStatuses[name] = status
+
+			if status == imageStatusOutdated || imageStatuses[image.ID] == "" {
+				imageStatuses[image.ID] = status
+			}
+
+			if status == imageStatusOutdated {
+				outdatedImages = true
+			}
+
+			fmt.Fprintf(writer, "%s\t%s\t%s\n", utils.ShortID(image.ID), name, status)
+		}
+	}
+
+	writer.Flush()
+
+	var outdatedContainers bool
+
+	if len(containers) != 0 {
+		fmt.Println()
+
+		writer = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", "CONTAINER NAME", "IMAGE NAME", "STATUS")
+
+		for _, container := range containers {
+			status := getContainerImageStatus(container, images, imageStatuses, imageNameStatuses)
+			if status == containerStatusImageOutdated || status == containerStatusImageUpdated {
+				outdatedContainers = true
+			}
+
+			fmt.Fprintf(writer, "%s\t%s\t%s\n", container.Names[0], container.Image, status)
+		}
+
+		writer.Flush()
+	}
+
+	if outdatedImages {
+		fmt.Printf("\nOutdated images can be updated with 'podman pull'.\n")
+	}
+
+	if outdatedContainers {
+		fmt.Printf("Recreate outdated containers with '%s create' to use the newer images.\n", executableBase)
+	}
+
+	return nil
+}
+
+func imageCheckHelp(cmd *cobra.Command, args []string) {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			fmt.Fprintf(os.Stderr, "Error: this is not a toolbox container\n")
+			return
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return
+		}
+
+		return
+	}
+
+	if err := utils.ShowManual("toolbox-image-check"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return
+	}
+}
+
+// checkImage compares the digest of the local image with the one in the
+// registry that the name refers to. Images that were built locally are not
+// checked.
+func checkImage(name string, image toolboxImage, timeout time.Duration) (string, error) {
+	domain := utils.ImageReferenceGetDomain(name)
+	if domain == "" || domain == "localhost" {
+		return imageStatusLocal, nil
+	}
+
+	logrus.Debugf("Checking image %s in its registry", name)
+
+	remoteImage, err := skopeo.Inspect(name, timeout)
+	if err != nil {
+		return imageStatusUnknown, err
+	}
+
+	logrus.Debugf("Digest of image %s in its registry is %s", name, remoteImage.Digest)
+
+	if imageHasDigest(image, remoteImage.Digest) {
+		return imageStatusUpToDate, nil
+	}
+
+	return imageStatusOutdated, nil
+}
+
+// getContainerImageStatus tells whether a container uses the newest local
+// image with the same name, and whether that image is up to date.
+func getContainerImageStatus(container toolboxContainer,
+	images []toolboxImage,
+	imageStatuses, imageNameStatuses map[string]string) string {
+	for _, image := range images {
+		if image.ID == container.ImageID {
+			continue
+		}
+
+		for _, name := range image.Names {
+			if name == container.Image {
+				return containerStatusImageUpdated
+			}
+		}
+	}
+
+	if status, ok := imageStatuses[container.ImageID]; ok {
+		if status == imageStatusOutdated {
+			return containerStatusImageOutdated
+		}
+
+		return status
+	}
+
+	if status, ok := imageNameStatuses[container.Image]; ok && status == imageStatusOutdated {
+		return containerStatusImageOutdated
+	}
+
+	return imageStatusUnknown
+}
+
+func imageHasDigest(image toolboxImage, digest string) bool {
+	if image.Digest == digest {
+		return true
+	}
+
+	for _, repoDigest := range image.RepoDigests {
+		if strings.HasSuffix(repoDigest, "@"+digest) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// showImageUpdateHint tells the user if a newer version of the container's
+// image is available in its registry. The check is opt-in, and is done at
+// most once per configured interval, to avoid slowing down 'enter'.
+func showImageUpdateHint(container string) {
+	if toolboxConfig == nil || !toolboxConfig.Image.CheckOnEnter {
+		return
+	}
+
+	interval := imageCheckDefaultInterval
+	if toolboxConfig.Image.CheckInterval.Duration != 0 {
+		interval = toolboxConfig.Image.CheckInterval.Duration
+	}
+
+	stateDirectory, err := utils.GetStateDirectory()
+	if err != nil {
+		logrus.Debugf("Checking image of container %s: %s", container, err)
+		return
+	}
+
+	stampPath := stateDirectory + "/image-check"
+
+	if fileInfo, err := os.Stat(stampPath); err == nil {
+		if lastCheck := fileInfo.ModTime(); time.Since(lastCheck) < interval {
+			logrus.Debugf("Checking image of container %s: last checked at %s", container, lastCheck)
+			return
+		}
+	}
+
+	info, err := podman.Inspect("container", container)
+	if err != nil {
+		logrus.Debugf("Checking image of container %s: failed to inspect container: %s", container, err)
+		return
+	}
+
+	imageName, _ := info["ImageName"].(string)
+	imageID, _ := info["Image"].(string)
+	if imageName == "" || imageID == "" {
+		logrus.Debugf("Checking image of container %s: failed to get the image", container)
+		return
+	}
+
+	imageInfo, err := podman.Inspect("image", imageID)
+	if err != nil {
+		logrus.Debugf("Checking image of container %s: failed to inspect image %s: %s",
+			container,
+			imageID,
+			err)
+		return
+	}
+
+	image := toolboxImage{ID: imageID}
+	image.Digest, _ = imageInfo["Digest"].(string)
+
+	repoDigests, _ := imageInfo["RepoDigests"].([]interface{})
+	for _, repoDigest := range repoDigests {
+		if repoDigestString, ok := repoDigest.(string); ok {
+			image.RepoDigests = append(image.RepoDigests, repoDigestString)
+		}
+	}
+
+	if err := os.MkdirAll(stateDirectory, 0700); err != nil {
+		logrus.Debugf("Checking image of container %s: failed to create state directory %s: %s",
+			container,
+			stateDirectory,
+			err)
+		return
+	}
+
+	// The stamp is updated once the image is known, but before asking the
+	// registry, so that an unreachable registry doesn't slow down every
+	// 'enter'.
+	if err := ioutil.WriteFile(stampPath, nil, 0644); err != nil {
+		logrus.Debugf("Checking image of container %s: failed to write stamp file %s: %s",
+			container,
+			stampPath,
+			err)
+		return
+	}
+
+	status, err := checkImage(imageName, image, imageCheckHintTimeout)
+	if err != nil {
+		logrus.Debugf("Checking image of container %s: %s", container, err)
+		return
+	}
+
+	if status != imageStatusOutdated {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "A newer version of image %s is available.\n", imageName)
+	fmt.Fprintf(os.Stderr, "Run '%s image check' for more information.\n", executableBase)
+}
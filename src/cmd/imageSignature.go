@@ -0,0 +1,88 @@
+/*
+ * Copyright © 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/containers/toolbox/pkg/signature"
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// verifyImage checks the signature of an image before a toolbox container is
+// created from it. With a cosign(1) key, the signature is checked right away,
+// and the image is returned with the digest that was signed, so that exactly
+// that image is pulled. Otherwise, the image is returned along with the
+// signature policy that pulling it must satisfy.
+func verifyImage(image, release string) (string, string, error) {
+	reference, err := utils.ParseImageReference(image)
+	if err != nil {
+		return "", "", err
+	}
+
+	imageFull := image
+	if reference.Domain == "" {
+		imageFull, err = utils.GetFullyQualifiedImageFromDistros(image, release)
+	}
+
+	if err != nil || utils.ImageReferenceGetDomain(imageFull) == "localhost" {
+		return "", "", fmt.Errorf("image %s can't be verified because it's not from a registry", image)
+	}
+
+	referenceFull, err := utils.ParseImageReference(imageFull)
+	if err != nil {
+		return "", "", err
+	}
+
+	if key := toolboxConfig.Signatures.Key; key != "" {
+		digest, err := signature.VerifyCosign(imageFull, key)
+		if err != nil {
+			return "", "", fmt.Errorf("image %s was rejected by the sigstore signature check with key %s: %w",
+				imageFull,
+				key,
+				err)
+		}
+
+		logrus.Debugf("Image %s is signed with key %s as %s", imageFull, key, digest)
+
+		imageSigned := referenceFull.Name() + "@" + digest
+		return imageSigned, "", nil
+	}
+
+	policyPath := toolboxConfig.Signatures.Policy
+	if policyPath == "" {
+		policyPath = signature.DefaultPolicyPath()
+	}
+
+	policy, err := signature.LoadPolicy(policyPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	scope, requirements := policy.Requirements(referenceFull)
+	logrus.Debugf("Image %s matches scope '%s' of signature policy %s", imageFull, scope, policyPath)
+
+	if signature.AcceptsUnsigned(requirements) {
+		return "", "", fmt.Errorf("image %s can't be verified because signature policy %s accepts unsigned images in scope '%s'",
+			imageFull,
+			policyPath,
+			scope)
+	}
+
+	return imageFull, policyPath, nil
+}
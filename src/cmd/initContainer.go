@@ -17,26 +17,91 @@
 package cmd
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"os/signal"
 	"os/user"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/containers/toolbox/pkg/config"
 	"github.com/containers/toolbox/pkg/shell"
+	"github.com/containers/toolbox/pkg/users"
 	"github.com/containers/toolbox/pkg/utils"
 	"github.com/fsnotify/fsnotify"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
+const (
+	caTrustAnchorPrefix = "toolbox-host-"
+)
+
+const (
+	initContainerPhaseFailed  = "failed"
+	initContainerPhaseOk      = "ok"
+	initContainerPhaseRunning = "running"
+
+	initContainerStateFailed       = "failed"
+	initContainerStateInitialized  = "initialized"
+	initContainerStateInitializing = "initializing"
+)
+
+// initContainerPhase records the outcome of one step of initializing a
+// container.
+type initContainerPhase struct {
+	Name     string        `json:"name"`
+	Result   string        `json:"result"`
+	Error    string        `json:"error,omitempty"`
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+}
+
+// initContainerStatus is written by the entry point of a toolbox container to
+// the runtime directory, so that the host can tell how far the
+// initialization got and why it failed.
+type initContainerStatus struct {
+	State       string               `json:"state"`
+	Phases      []initContainerPhase `json:"phases"`
+	Maintenance []maintenanceTaskRun `json:"maintenance,omitempty"`
+
+	gid      int
+	lastPath string
+	path     string
+	uid      int
+}
+
 var (
+	// initContainerCATrustAnchors holds the locations of the host's trust
+	// anchors as seen from inside the container
+	initContainerCATrustAnchors = []string{
+		"/run/host/etc/pki/ca-trust/source/anchors",
+		"/run/host/usr/local/share/ca-certificates",
+	}
+
+	// initContainerCATrustStores holds the known ways of adding trust
+	// anchors to the container's operating system, in order of preference
+	initContainerCATrustStores = []struct {
+		anchors   string
+		command   string
+		args      []string
+		extension string
+	}{
+		{"/etc/pki/ca-trust/source/anchors", "update-ca-trust", []string{"extract"}, ""},
+		{"/usr/local/share/ca-certificates", "update-ca-certificates", nil, ".crt"},
+		{"/etc/ca-certificates/trust-source/anchors", "trust", []string{"extract-compat"}, ""},
+	}
+
 	initContainerFlags struct {
 		gid         int
+		groups      []string
 		home        string
 		homeLink    bool
 		mediaLink   bool
@@ -81,6 +146,11 @@ func init() {
 		0,
 		"Create a user inside the toolbox container whose numerical group ID is GID")
 
+	flags.StringSliceVar(&initContainerFlags.groups,
+		"groups",
+		nil,
+		"Add the user inside the toolbox container to the supplementary groups NAME:GID")
+
 	flags.StringVar(&initContainerFlags.home,
 		"home",
 		"",
@@ -140,77 +210,98 @@ func initContainer(cmd *cobra.Command, args []string) error {
 		initContainerFlags.gid = initContainerFlags.uid
 	}
 
+	hostGroups, err := parseGroups(initContainerFlags.groups)
+	if err != nil {
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "invalid argument for '--groups'\n")
+		fmt.Fprintf(&builder, "%s\n", err)
+		fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+		errMsg := builder.String()
+		return errors.New(errMsg)
+	}
+
 	utils.EnsureXdgRuntimeDirIsSet(initContainerFlags.uid)
 
+	targetUser := &user.User{
+		Gid: strconv.Itoa(initContainerFlags.gid),
+		Uid: strconv.Itoa(initContainerFlags.uid),
+	}
+
+	toolboxRuntimeDirectory, err := utils.GetRuntimeDirectory(targetUser)
+	if err != nil {
+		return err
+	}
+
+	pid := os.Getpid()
+	statusPath := fmt.Sprintf("%s/container-status-%d.json", toolboxRuntimeDirectory, pid)
+
+	logrus.Debugf("Recording initialization status in %s", statusPath)
+
+	status := newInitContainerStatus(statusPath, initContainerFlags.uid, initContainerFlags.gid)
+
+	// The status is also kept under the container's ID, so that it can
+	// still be shown after the container stopped
+	if containerID := getCurrentContainerID(); containerID != "" {
+		status.lastPath = getInitContainerLastStatusPath(toolboxRuntimeDirectory, containerID)
+		logrus.Debugf("Recording last initialization status in %s", status.lastPath)
+	}
+
+	status.write()
+
 	logrus.Debug("Creating /run/.toolboxenv")
 
 	toolboxEnvFile, err := os.Create("/run/.toolboxenv")
 	if err != nil {
-		return errors.New("failed to create /run/.toolboxenv")
+		err := errors.New("failed to create /run/.toolboxenv")
+		status.fail("Creating /run/.toolboxenv", err)
+		return err
 	}
 
 	defer toolboxEnvFile.Close()
 
+	logrus.Debug("Setting up signal handlers")
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
 	if initContainerFlags.monitorHost {
 		logrus.Debug("Monitoring host")
 
 		if utils.PathExists("/run/host/etc") {
 			logrus.Debug("Path /run/host/etc exists")
 
-			if _, err := os.Readlink("/etc/host.conf"); err != nil {
-				if err := redirectPath("/etc/host.conf",
-					"/run/host/etc/host.conf",
-					false); err != nil {
-					return err
-				}
-			}
-
-			if _, err := os.Readlink("/etc/hosts"); err != nil {
-				if err := redirectPath("/etc/hosts",
-					"/run/host/etc/hosts",
-					false); err != nil {
-					return err
-				}
-			}
-
-			if localtimeTarget, err := os.Readlink("/etc/localtime"); err != nil ||
-				localtimeTarget != "/run/host/etc/localtime" {
-				if err := redirectPath("/etc/localtime",
-					"/run/host/etc/localtime",
-					false); err != nil {
-					return err
-				}
-			}
-
-			if err := updateTimeZoneFromLocalTime(); err != nil {
+			if err := syncWithHost(status); err != nil {
 				return err
 			}
 
-			if _, err := os.Readlink("/etc/resolv.conf"); err != nil {
-				if err := redirectPath("/etc/resolv.conf",
-					"/run/host/etc/resolv.conf",
-					false); err != nil {
-					return err
-				}
-			}
-
 			for _, mount := range initContainerMounts {
-				if err := mountBind(mount.containerPath, mount.source, mount.flags); err != nil {
+				phase := "Binding " + mount.containerPath
+				if err := status.runPhase(phase, func() error {
+					return mountBind(mount.containerPath, mount.source, mount.flags)
+				}); err != nil {
 					return err
 				}
 			}
 
 			if utils.PathExists("/sys/fs/selinux") {
-				if err := mountBind("/sys/fs/selinux", "/usr/share/empty", ""); err != nil {
+				if err := status.runPhase("Binding /sys/fs/selinux", func() error {
+					return mountBind("/sys/fs/selinux", "/usr/share/empty", "")
+				}); err != nil {
 					return err
 				}
 			}
 		}
 	}
 
+	status.runOptionalPhase("Importing trust anchors", updateCATrust)
+
 	if initContainerFlags.mediaLink {
 		if _, err := os.Readlink("/media"); err != nil {
-			if err = redirectPath("/media", "/run/media", true); err != nil {
+			if err := status.runPhase("Redirecting /media", func() error {
+				return redirectPath("/media", "/run/media", true)
+			}); err != nil {
 				return err
 			}
 		}
@@ -218,121 +309,117 @@ func initContainer(cmd *cobra.Command, args []string) error {
 
 	if initContainerFlags.mntLink {
 		if _, err := os.Readlink("/mnt"); err != nil {
-			if err := redirectPath("/mnt", "/var/mnt", true); err != nil {
+			if err := status.runPhase("Redirecting /mnt", func() error {
+				return redirectPath("/mnt", "/var/mnt", true)
+			}); err != nil {
 				return err
 			}
 		}
 	}
 
-	if _, err := user.Lookup(initContainerFlags.user); err != nil {
-		if err := configureUsers(initContainerFlags.uid,
-			initContainerFlags.user,
-			initContainerFlags.home,
-			initContainerFlags.shell,
-			initContainerFlags.homeLink,
-			false); err != nil {
-			return err
-		}
-	} else {
-		if err := configureUsers(initContainerFlags.uid,
+	var targetUserExists bool
+	if _, err := user.Lookup(initContainerFlags.user); err == nil {
+		targetUserExists = true
+	}
+
+	if err := status.runPhase("Configuring users", func() error {
+		return configureUsers(initContainerFlags.uid,
+			initContainerFlags.gid,
 			initContainerFlags.user,
 			initContainerFlags.home,
 			initContainerFlags.shell,
+			hostGroups,
 			initContainerFlags.homeLink,
-			true); err != nil {
-			return err
-		}
+			targetUserExists)
+	}); err != nil {
+		return err
 	}
 
 	if utils.PathExists("/etc/krb5.conf.d") && !utils.PathExists("/etc/krb5.conf.d/kcm_default_ccache") {
-		logrus.Debug("Setting KCM as the default Kerberos credential cache")
-
-		kcmConfigString := `# Written by Toolbox
-# https://github.com/containers/toolbox
-#
-# # To disable the KCM credential cache, comment out the following lines.
-
-[libdefaults]
-    default_ccache_name = KCM:
-`
-
-		kcmConfigBytes := []byte(kcmConfigString)
-		if err := ioutil.WriteFile("/etc/krb5.conf.d/kcm_default_ccache",
-			kcmConfigBytes,
-			0644); err != nil {
-			return errors.New("failed to set KCM as the defult Kerberos credential cache")
+		if err := status.runPhase("Configuring KCM", configureKCM); err != nil {
+			return err
 		}
 	}
 
-	logrus.Debug("Setting up daily ticker")
+	logrus.Debug("Setting up maintenance tasks")
 
-	daily, err := time.ParseDuration("24h")
-	if err != nil {
-		panicMsg := fmt.Sprintf("failed to parse duration: %v", err)
-		panic(panicMsg)
-	}
-
-	tickerDaily := time.NewTicker(daily)
-	defer tickerDaily.Stop()
+	maintenanceConfig := loadInitContainerConfig(initContainerFlags.home)
+	maintenanceTasks := getMaintenanceTasks(maintenanceConfig.Maintenance)
+	maintenance := newMaintenanceScheduler(maintenanceTasks)
+	defer maintenance.stop()
 
 	logrus.Debug("Setting up watches for file system events")
 
 	watcherForHost, err := fsnotify.NewWatcher()
 	if err != nil {
+		status.fail("Setting up watches for file system events", err)
 		return err
 	}
 
 	defer watcherForHost.Close()
 
 	if err := watcherForHost.Add("/run/host/etc"); err != nil {
+		status.fail("Setting up watches for file system events", err)
 		return err
 	}
 
-	logrus.Debug("Finished initializing container")
+	if initContainerFlags.monitorHost {
+		for _, anchors := range initContainerCATrustAnchors {
+			if !utils.PathExists(anchors) {
+				continue
+			}
 
-	uidString := strconv.Itoa(initContainerFlags.uid)
-	targetUser, err := user.LookupId(uidString)
-	if err != nil {
-		return fmt.Errorf("failed to lookup user ID %s: %w", uidString, err)
-	}
+			logrus.Debugf("Watching %s", anchors)
 
-	toolboxRuntimeDirectory, err := utils.GetRuntimeDirectory(targetUser)
-	if err != nil {
-		return err
+			if err := watcherForHost.Add(anchors); err != nil {
+				status.fail("Setting up watches for file system events", err)
+				return err
+			}
+		}
 	}
 
-	pid := os.Getpid()
-	initializedStamp := fmt.Sprintf("%s/container-initialized-%d", toolboxRuntimeDirectory, pid)
-
-	logrus.Debugf("Creating initialization stamp %s", initializedStamp)
-
-	initializedStampFile, err := os.Create(initializedStamp)
-	if err != nil {
-		return errors.New("failed to create initialization stamp")
-	}
+	logrus.Debug("Finished initializing container")
 
-	defer initializedStampFile.Close()
+	initializedStamp := fmt.Sprintf("%s/container-initialized-%d", toolboxRuntimeDirectory, pid)
 
-	if err := initializedStampFile.Chown(initContainerFlags.uid, initContainerFlags.gid); err != nil {
-		return errors.New("failed to change ownership of initialization stamp")
+	if err := status.runPhase("Creating initialization stamp", func() error {
+		return createInitializedStamp(initializedStamp)
+	}); err != nil {
+		return err
 	}
 
-	logrus.Debug("Listening to file system and ticker events")
+	status.State = initContainerStateInitialized
+	status.write()
 
-	go runUpdateDb()
+	logrus.Debug("Listening to file system, signal and timer events")
 
 	for {
 		select {
-		case event := <-tickerDaily.C:
-			handleDailyTick(event)
+		case <-maintenance.C():
+			maintenance.runDue()
+		case run := <-maintenance.results:
+			maintenance.finish(run)
+			status.recordMaintenanceTaskRun(run)
 		case event := <-watcherForHost.Events:
 			handleFileSystemEvent(event)
 		case err := <-watcherForHost.Errors:
 			logrus.Warnf("Received an error from the file system watcher: %v", err)
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				handleHangUp()
+				continue
+			}
+
+			logrus.Debugf("Received signal %s, shutting down", sig)
+
+			if err := watcherForHost.Close(); err != nil {
+				logrus.Warnf("Failed to close the file system watcher: %v", err)
+			}
+
+			shutDownContainer(initializedStamp, statusPath)
+			return nil
 		}
 	}
-
-	return nil
 }
 
 func initContainerHelp(cmd *cobra.Command, args []string) {
@@ -356,8 +443,9 @@ func initContainerHelp(cmd *cobra.Command, args []string) {
 	}
 }
 
-func configureUsers(targetUserUid int,
+func configureUsers(targetUserUid, targetUserGid int,
 	targetUser, targetUserHome, targetUserShell string,
+	hostGroups []users.Group,
 	homeLink, targetUserExists bool) error {
 	if homeLink {
 		if err := redirectPath("/home", "/var/home", true); err != nil {
@@ -365,73 +453,145 @@ func configureUsers(targetUserUid int,
 		}
 	}
 
+	var groups []string
+
 	sudoGroup, err := utils.GetGroupForSudo()
 	if err != nil {
-		return fmt.Errorf("failed to get group for sudo: %w", err)
+		logrus.Debugf("Looking up group for sudo failed: %s", err)
+	} else {
+		groups = append(groups, sudoGroup)
 	}
 
-	if targetUserExists {
-		logrus.Debugf("Modifying user %s with UID %d:", targetUser, targetUserUid)
+	userManager := users.NewManager()
+	logrus.Debugf("Managing users with %s", userManager.Name())
 
-		usermodArgs := []string{
-			"--append",
-			"--groups", sudoGroup,
-			"--home", targetUserHome,
-			"--shell", targetUserShell,
-			"--uid", fmt.Sprint(targetUserUid),
-			targetUser,
+	for _, hostGroup := range hostGroups {
+		group, err := ensureGroup(userManager, hostGroup)
+		if err != nil {
+			logrus.Warnf("Failed to mirror group %s from the host: %v", hostGroup.Name, err)
+			continue
 		}
 
-		logrus.Debug("usermod")
-		for _, arg := range usermodArgs {
-			logrus.Debugf("%s", arg)
+		if group != sudoGroup {
+			groups = append(groups, group)
 		}
+	}
 
-		if err := shell.Run("usermod", nil, nil, nil, usermodArgs...); err != nil {
-			return fmt.Errorf("failed to modify user %s with UID %d", targetUser, targetUserUid)
-		}
-	} else {
-		logrus.Debugf("Adding user %s with UID %d:", targetUser, targetUserUid)
+	targetUserObj := &users.User{
+		Name:   targetUser,
+		UID:    targetUserUid,
+		GID:    targetUserGid,
+		Home:   targetUserHome,
+		Shell:  targetUserShell,
+		Groups: groups,
+	}
 
-		useraddArgs := []string{
-			"--groups", sudoGroup,
-			"--home-dir", targetUserHome,
-			"--no-create-home",
-			"--shell", targetUserShell,
-			"--uid", fmt.Sprint(targetUserUid),
-			targetUser,
+	if targetUserExists {
+		if err := userManager.ModifyUser(targetUserObj); err != nil {
+			return err
 		}
-
-		logrus.Debug("useradd")
-		for _, arg := range useraddArgs {
-			logrus.Debugf("%s", arg)
+	} else {
+		if err := userManager.AddUser(targetUserObj); err != nil {
+			return err
 		}
+	}
 
-		if err := shell.Run("useradd", nil, nil, nil, useraddArgs...); err != nil {
-			return fmt.Errorf("failed to add user %s with UID %d", targetUser, targetUserUid)
+	if sudoGroup == "" {
+		if err := users.ConfigureAdmin(targetUser); err != nil {
+			logrus.Warnf("Failed to grant user %s root access: %v", targetUser, err)
 		}
 	}
 
 	logrus.Debugf("Removing password for user %s", targetUser)
 
-	if err := shell.Run("passwd", nil, nil, nil, "--delete", targetUser); err != nil {
-		return fmt.Errorf("failed to remove password for user %s", targetUser)
+	if err := userManager.DeletePassword(targetUser); err != nil {
+		return err
 	}
 
 	logrus.Debug("Removing password for user root")
 
-	if err := shell.Run("passwd", nil, nil, nil, "--delete", "root"); err != nil {
+	if err := userManager.DeletePassword("root"); err != nil {
 		return errors.New("failed to remove password for root")
 	}
 
 	return nil
 }
 
-func handleDailyTick(event time.Time) {
-	eventString := event.String()
-	logrus.Debugf("Handling daily tick %s", eventString)
+func configureKCM() error {
+	logrus.Debug("Setting KCM as the default Kerberos credential cache")
+
+	kcmConfigString := `# Written by Toolbox
+# https://github.com/containers/toolbox
+#
+# # To disable the KCM credential cache, comment out the following lines.
+
+[libdefaults]
+    default_ccache_name = KCM:
+`
+
+	kcmConfigBytes := []byte(kcmConfigString)
+	if err := ioutil.WriteFile("/etc/krb5.conf.d/kcm_default_ccache",
+		kcmConfigBytes,
+		0644); err != nil {
+		return errors.New("failed to set KCM as the defult Kerberos credential cache")
+	}
+
+	return nil
+}
+
+func createInitializedStamp(initializedStamp string) error {
+	logrus.Debugf("Creating initialization stamp %s", initializedStamp)
+
+	initializedStampFile, err := os.Create(initializedStamp)
+	if err != nil {
+		return errors.New("failed to create initialization stamp")
+	}
+
+	defer initializedStampFile.Close()
+
+	if err := initializedStampFile.Chown(initContainerFlags.uid, initContainerFlags.gid); err != nil {
+		return errors.New("failed to change ownership of initialization stamp")
+	}
 
-	runUpdateDb()
+	return nil
+}
+
+// ensureGroup returns the name of the group inside the container that has the
+// same numerical group ID as hostGroup, and adds it if there's none. If the
+// container already has a group with the same name but a different GID, the
+// group is added as host-NAME instead, because access to devices and files is
+// granted by GID.
+func ensureGroup(userManager users.Manager, hostGroup users.Group) (string, error) {
+	gidString := strconv.Itoa(hostGroup.GID)
+	if group, err := user.LookupGroupId(gidString); err == nil {
+		logrus.Debugf("Group %s with GID %d already exists as %s", hostGroup.Name, hostGroup.GID, group.Name)
+		return group.Name, nil
+	}
+
+	name := hostGroup.Name
+
+	if group, err := user.LookupGroup(name); err == nil {
+		name = "host-" + hostGroup.Name
+
+		logrus.Debugf("Group %s already exists with GID %s instead of %d, so adding it as %s",
+			hostGroup.Name,
+			group.Gid,
+			hostGroup.GID,
+			name)
+
+		if group, err := user.LookupGroup(name); err == nil {
+			return "", fmt.Errorf("group %s already exists with GID %s instead of %d",
+				name,
+				group.Gid,
+				hostGroup.GID)
+		}
+	}
+
+	if err := userManager.AddGroup(name, hostGroup.GID); err != nil {
+		return "", err
+	}
+
+	return name, nil
 }
 
 func handleFileSystemEvent(event fsnotify.Event) {
@@ -443,6 +603,53 @@ func handleFileSystemEvent(event fsnotify.Event) {
 			logrus.Warnf("Failed to handle changes to the host's /etc/localtime: %v", err)
 		}
 	}
+
+	eventDir := filepath.Dir(event.Name)
+	for _, anchors := range initContainerCATrustAnchors {
+		if eventDir != anchors {
+			continue
+		}
+
+		if err := updateCATrust(); err != nil {
+			logrus.Warnf("Failed to handle changes to the host's trust anchors: %v", err)
+		}
+
+		break
+	}
+}
+
+func handleHangUp() {
+	logrus.Debug("Handling SIGHUP")
+
+	if initContainerFlags.monitorHost && utils.PathExists("/run/host/etc") {
+		if err := syncWithHost(nil); err != nil {
+			logrus.Warnf("Failed to synchronize with the host: %v", err)
+		}
+	}
+
+	if err := updateCATrust(); err != nil {
+		logrus.Warnf("Failed to update the trust anchors from the host: %v", err)
+	}
+}
+
+// loadInitContainerConfig reads the configuration files from the host and
+// from the user's home directory, which is shared with the container. Broken
+// files are reported, but they don't prevent the container from starting.
+func loadInitContainerConfig(home string) *config.Config {
+	paths := []string{"/run/host/etc/containers/toolbox.conf"}
+
+	if home != "" {
+		userConfigFile := filepath.Join(home, ".config", "containers", "toolbox.conf")
+		paths = append(paths, userConfigFile)
+	}
+
+	initContainerConfig, err := config.LoadFiles(paths...)
+	if err != nil {
+		logrus.Warnf("Failed to load the configuration, using the defaults: %v", err)
+		return &config.Config{}
+	}
+
+	return initContainerConfig
 }
 
 func mountBind(containerPath, source, flags string) error {
@@ -492,6 +699,27 @@ func mountBind(containerPath, source, flags string) error {
 	return nil
 }
 
+// parseGroups parses supplementary groups specified as NAME:GID.
+func parseGroups(values []string) ([]users.Group, error) {
+	var groups []users.Group
+
+	for _, value := range values {
+		i := strings.LastIndex(value, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("group %s must be specified as NAME:GID", value)
+		}
+
+		gid, err := strconv.Atoi(value[i+1:])
+		if err != nil || gid < 0 {
+			return nil, fmt.Errorf("group %s has an invalid GID", value)
+		}
+
+		groups = append(groups, users.Group{Name: value[:i], GID: gid})
+	}
+
+	return groups, nil
+}
+
 // redirectPath serves for creating symbolic links for crucial system
 // configuration files to their counterparts on the host's filesystem.
 //
@@ -554,10 +782,114 @@ func redirectPath(containerPath, target string, folder bool) error {
 	return nil
 }
 
-func runUpdateDb() {
-	if err := shell.Run("updatedb", nil, nil, nil); err != nil {
-		logrus.Warnf("Failed to run updatedb(8): %v", err)
+// updateCATrust imports the trust anchors from the host into the container's
+// trust store, and removes those that were previously imported but are no
+// longer present on the host.
+//
+// It's not an error if the container doesn't have any known tool to update
+// the trust store, because some images don't ship one. Trust anchors that
+// can't be read, like symbolic links that only resolve on the host, are
+// skipped with a warning.
+func updateCATrust() error {
+	var hostAnchors []string
+
+	for _, anchors := range initContainerCATrustAnchors {
+		fileInfos, err := ioutil.ReadDir(anchors)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+
+			return fmt.Errorf("failed to read %s: %w", anchors, err)
+		}
+
+		for _, fileInfo := range fileInfos {
+			if fileInfo.IsDir() {
+				continue
+			}
+
+			hostAnchor := filepath.Join(anchors, fileInfo.Name())
+			hostAnchors = append(hostAnchors, hostAnchor)
+		}
+	}
+
+	logrus.Debugf("Found %d trust anchors on the host", len(hostAnchors))
+
+	for _, store := range initContainerCATrustStores {
+		if _, err := exec.LookPath(store.command); err != nil {
+			continue
+		}
+
+		logrus.Debugf("Updating trust anchors in %s", store.anchors)
+
+		if err := os.MkdirAll(store.anchors, 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", store.anchors, err)
+		}
+
+		imported := make(map[string]bool)
+
+		for _, hostAnchor := range hostAnchors {
+			basename := filepath.Base(hostAnchor)
+			name := caTrustAnchorPrefix + strings.TrimSuffix(basename, filepath.Ext(basename))
+			if store.extension != "" {
+				name = name + store.extension
+			} else {
+				name = name + filepath.Ext(basename)
+			}
+
+			anchorBytes, err := ioutil.ReadFile(hostAnchor)
+			if err != nil {
+				logrus.Warnf("Skipping trust anchor %s: %v", hostAnchor, err)
+				continue
+			}
+
+			anchor := filepath.Join(store.anchors, name)
+			if err := ioutil.WriteFile(anchor, anchorBytes, 0644); err != nil {
+				return fmt.Errorf("failed to import trust anchor %s: %w", hostAnchor, err)
+			}
+
+			imported[name] = true
+		}
+
+		fileInfos, err := ioutil.ReadDir(store.anchors)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", store.anchors, err)
+		}
+
+		removed := 0
+
+		for _, fileInfo := range fileInfos {
+			name := fileInfo.Name()
+			if !strings.HasPrefix(name, caTrustAnchorPrefix) || imported[name] {
+				continue
+			}
+
+			anchor := filepath.Join(store.anchors, name)
+			logrus.Debugf("Removing stale trust anchor %s", anchor)
+
+			if err := os.Remove(anchor); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove stale trust anchor %s: %w", anchor, err)
+			}
+
+			removed++
+		}
+
+		if len(imported) == 0 && removed == 0 {
+			return nil
+		}
+
+		if err := shell.Run(store.command, nil, nil, nil, store.args...); err != nil {
+			return fmt.Errorf("failed to update the trust store with %s: %w", store.command, err)
+		}
+
+		return nil
 	}
+
+	if len(hostAnchors) != 0 {
+		logrus.Warn("Failed to import the host's trust anchors: no known tool to update the trust store")
+	}
+
+	return nil
 }
 
 func sanitizeRedirectionTarget(target string) string {
@@ -630,6 +962,73 @@ func extractTimeZoneFromLocalTimeSymLink(path string) (string, error) {
 	return "", errors.New("/etc/localtime points to unknown location")
 }
 
+// shutDownContainer undoes the parts of the initialization that would
+// otherwise make a stopped container look like an initialized one.
+func shutDownContainer(initializedStamp, statusPath string) {
+	logrus.Debugf("Removing initialization stamp %s", initializedStamp)
+
+	if err := os.Remove(initializedStamp); err != nil && !os.IsNotExist(err) {
+		logrus.Warnf("Failed to remove initialization stamp %s: %v", initializedStamp, err)
+	}
+
+	logrus.Debugf("Removing initialization status %s", statusPath)
+
+	if err := os.Remove(statusPath); err != nil && !os.IsNotExist(err) {
+		logrus.Warnf("Failed to remove initialization status %s: %v", statusPath, err)
+	}
+
+	logrus.Debug("Removing /run/.toolboxenv")
+
+	if err := os.Remove("/run/.toolboxenv"); err != nil && !os.IsNotExist(err) {
+		logrus.Warnf("Failed to remove /run/.toolboxenv: %v", err)
+	}
+}
+
+// syncWithHost ensures that crucial configuration files inside the container
+// point to their counterparts on the host.
+//
+// status may be nil if the outcome doesn't need to be recorded.
+func syncWithHost(status *initContainerStatus) error {
+	if _, err := os.Readlink("/etc/host.conf"); err != nil {
+		if err := status.runPhase("Redirecting /etc/host.conf", func() error {
+			return redirectPath("/etc/host.conf", "/run/host/etc/host.conf", false)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if _, err := os.Readlink("/etc/hosts"); err != nil {
+		if err := status.runPhase("Redirecting /etc/hosts", func() error {
+			return redirectPath("/etc/hosts", "/run/host/etc/hosts", false)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if localtimeTarget, err := os.Readlink("/etc/localtime"); err != nil ||
+		localtimeTarget != "/run/host/etc/localtime" {
+		if err := status.runPhase("Redirecting /etc/localtime", func() error {
+			return redirectPath("/etc/localtime", "/run/host/etc/localtime", false)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := status.runPhase("Updating /etc/timezone", updateTimeZoneFromLocalTime); err != nil {
+		return err
+	}
+
+	if _, err := os.Readlink("/etc/resolv.conf"); err != nil {
+		if err := status.runPhase("Redirecting /etc/resolv.conf", func() error {
+			return redirectPath("/etc/resolv.conf", "/run/host/etc/resolv.conf", false)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func updateTimeZoneFromLocalTime() error {
 	localTimeEvaled, err := filepath.EvalSymlinks("/etc/localtime")
 	if err != nil {
@@ -674,3 +1073,182 @@ func writeTimeZone(timeZone string) error {
 
 	return nil
 }
+
+// getCurrentContainerID returns the ID of the container that this is running
+// in, as recorded by Podman in /run/.containerenv, or an empty string if it's
+// unknown.
+func getCurrentContainerID() string {
+	data, err := ioutil.ReadFile("/run/.containerenv")
+	if err != nil {
+		logrus.Debugf("Reading /run/.containerenv failed: %s", err)
+		return ""
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if value := strings.TrimPrefix(line, "id="); value != line {
+			containerID := strings.Trim(value, "\"")
+			return containerID
+		}
+	}
+
+	return ""
+}
+
+func getInitContainerLastStatusPath(toolboxRuntimeDirectory, containerID string) string {
+	lastStatusPath := fmt.Sprintf("%s/container-last-status-%s.json", toolboxRuntimeDirectory, containerID)
+	return lastStatusPath
+}
+
+func newInitContainerStatus(path string, uid, gid int) *initContainerStatus {
+	status := &initContainerStatus{
+		State: initContainerStateInitializing,
+		gid:   gid,
+		path:  path,
+		uid:   uid,
+	}
+
+	return status
+}
+
+func readInitContainerStatus(path string) (*initContainerStatus, error) {
+	statusBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var status initContainerStatus
+	if err := json.Unmarshal(statusBytes, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse initialization status %s: %w", path, err)
+	}
+
+	status.path = path
+	return &status, nil
+}
+
+// currentPhase returns the phase that failed or is still running, if any.
+func (status *initContainerStatus) currentPhase() *initContainerPhase {
+	for i := range status.Phases {
+		phase := &status.Phases[i]
+		if phase.Result != initContainerPhaseOk {
+			return phase
+		}
+	}
+
+	return nil
+}
+
+// fail records a failure that happened outside any phase.
+func (status *initContainerStatus) fail(name string, err error) {
+	status.Phases = append(status.Phases, initContainerPhase{
+		Name:    name,
+		Result:  initContainerPhaseFailed,
+		Error:   err.Error(),
+		Started: time.Now(),
+	})
+
+	status.State = initContainerStateFailed
+	status.write()
+}
+
+// runPhase runs phase and records its outcome under the given name. It's
+// safe to call on a nil status, in which case nothing is recorded.
+func (status *initContainerStatus) runPhase(name string, phase func() error) error {
+	err := status.recordPhase(name, phase)
+	if err != nil && status != nil {
+		status.State = initContainerStateFailed
+		status.write()
+	}
+
+	return err
+}
+
+// runOptionalPhase is like runPhase, but a failure of phase is only logged as
+// a warning and doesn't fail the initialization.
+func (status *initContainerStatus) runOptionalPhase(name string, phase func() error) {
+	if err := status.recordPhase(name, phase); err != nil {
+		logrus.Warnf("%s failed: %v", name, err)
+	}
+}
+
+func (status *initContainerStatus) recordPhase(name string, phase func() error) error {
+	if status == nil {
+		return phase()
+	}
+
+	status.Phases = append(status.Phases, initContainerPhase{
+		Name:    name,
+		Result:  initContainerPhaseRunning,
+		Started: time.Now(),
+	})
+
+	i := len(status.Phases) - 1
+	status.write()
+
+	err := phase()
+
+	status.Phases[i].Duration = time.Since(status.Phases[i].Started)
+
+	if err != nil {
+		status.Phases[i].Result = initContainerPhaseFailed
+		status.Phases[i].Error = err.Error()
+	} else {
+		status.Phases[i].Result = initContainerPhaseOk
+	}
+
+	status.write()
+	return err
+}
+
+// write atomically replaces the status file, and the copy kept under the
+// container's ID, if any. Failures are only logged, because they shouldn't
+// prevent the container from being initialized.
+func (status *initContainerStatus) write() {
+	statusBytes, err := json.Marshal(status)
+	if err != nil {
+		logrus.Warnf("Failed to marshal initialization status: %v", err)
+		return
+	}
+
+	status.writeFile(status.path, statusBytes)
+
+	if status.lastPath != "" {
+		status.writeFile(status.lastPath, statusBytes)
+	}
+}
+
+func (status *initContainerStatus) writeFile(path string, statusBytes []byte) {
+	statusDir := filepath.Dir(path)
+	statusFile, err := ioutil.TempFile(statusDir, ".container-status-")
+	if err != nil {
+		logrus.Warnf("Failed to write initialization status %s: %v", path, err)
+		return
+	}
+
+	statusFileName := statusFile.Name()
+	defer os.Remove(statusFileName)
+
+	_, err = statusFile.Write(statusBytes)
+	if closeErr := statusFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		logrus.Warnf("Failed to write initialization status %s: %v", path, err)
+		return
+	}
+
+	if err := os.Chmod(statusFileName, 0644); err != nil {
+		logrus.Warnf("Failed to change permissions of initialization status %s: %v", path, err)
+		return
+	}
+
+	if err := os.Chown(statusFileName, status.uid, status.gid); err != nil {
+		logrus.Warnf("Failed to change ownership of initialization status %s: %v", path, err)
+		return
+	}
+
+	if err := os.Rename(statusFileName, path); err != nil {
+		logrus.Warnf("Failed to write initialization status %s: %v", path, err)
+		return
+	}
+}
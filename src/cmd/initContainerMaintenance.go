@@ -0,0 +1,332 @@
+/*
+ * Copyright © 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"math/rand"
+	"os/exec"
+	"sort"
+	"time"
+
+	"github.com/containers/toolbox/pkg/config"
+	"github.com/containers/toolbox/pkg/shell"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	maintenanceHistoryLength = 20
+)
+
+// maintenanceTask is a command that's periodically run inside a toolbox
+// container by its entry point.
+type maintenanceTask struct {
+	name     string
+	command  []string
+	interval time.Duration
+	jitter   time.Duration
+	next     time.Time
+	running  bool
+}
+
+// maintenanceTaskRun records the outcome of one run of a maintenance task.
+type maintenanceTaskRun struct {
+	Task     string        `json:"task"`
+	Result   string        `json:"result"`
+	Error    string        `json:"error,omitempty"`
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+}
+
+// maintenanceScheduler runs maintenance tasks in the background and reports
+// their outcome through the results channel, so that the main loop of the
+// entry point remains responsive to signals and file system events.
+type maintenanceScheduler struct {
+	random  *rand.Rand
+	results chan maintenanceTaskRun
+	tasks   []*maintenanceTask
+	timer   *time.Timer
+}
+
+var (
+	// maintenanceBuiltInTasks holds the tasks that Toolbox knows about. For
+	// each task, the first command that's available inside the container is
+	// used.
+	maintenanceBuiltInTasks = []struct {
+		name     string
+		commands [][]string
+		enabled  bool
+		interval time.Duration
+	}{
+		{
+			"updatedb",
+			[][]string{{"updatedb"}},
+			true,
+			24 * time.Hour,
+		},
+		{
+			"refresh-metadata",
+			[][]string{
+				{"dnf", "--quiet", "makecache"},
+				{"apt-get", "--quiet", "update"},
+				{"apk", "update"},
+				{"zypper", "--quiet", "refresh"},
+			},
+			false,
+			24 * time.Hour,
+		},
+		{
+			"clean-cache",
+			[][]string{
+				{"dnf", "--quiet", "clean", "packages"},
+				{"apt-get", "clean"},
+				{"apk", "cache", "clean"},
+				{"zypper", "--quiet", "clean"},
+			},
+			false,
+			7 * 24 * time.Hour,
+		},
+	}
+)
+
+// getMaintenanceTasks combines the built-in tasks with the user's
+// configuration. Tasks whose commands aren't available inside the container
+// are left out.
+func getMaintenanceTasks(maintenanceConfig map[string]config.MaintenanceTask) []*maintenanceTask {
+	var tasks []*maintenanceTask
+
+	builtIn := make(map[string]bool)
+
+	for _, builtInTask := range maintenanceBuiltInTasks {
+		builtIn[builtInTask.name] = true
+
+		taskConfig := maintenanceConfig[builtInTask.name]
+
+		enabled := builtInTask.enabled
+		if taskConfig.Enabled != nil {
+			enabled = *taskConfig.Enabled
+		}
+
+		if !enabled {
+			logrus.Debugf("Maintenance task %s is disabled", builtInTask.name)
+			continue
+		}
+
+		command := taskConfig.Command
+		if len(command) == 0 {
+			for _, candidate := range builtInTask.commands {
+				if _, err := exec.LookPath(candidate[0]); err == nil {
+					command = candidate
+					break
+				}
+			}
+		}
+
+		if len(command) == 0 {
+			logrus.Debugf("Maintenance task %s isn't supported in this container", builtInTask.name)
+			continue
+		}
+
+		interval := builtInTask.interval
+		if taskConfig.Interval.Duration != 0 {
+			interval = taskConfig.Interval.Duration
+		}
+
+		task := &maintenanceTask{
+			name:     builtInTask.name,
+			command:  command,
+			interval: interval,
+			jitter:   taskConfig.Jitter.Duration,
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	var names []string
+	for name := range maintenanceConfig {
+		if !builtIn[name] {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		taskConfig := maintenanceConfig[name]
+
+		if taskConfig.Enabled != nil && !*taskConfig.Enabled {
+			logrus.Debugf("Maintenance task %s is disabled", name)
+			continue
+		}
+
+		if len(taskConfig.Command) == 0 {
+			logrus.Warnf("Maintenance task %s has no command", name)
+			continue
+		}
+
+		if taskConfig.Interval.Duration == 0 {
+			logrus.Warnf("Maintenance task %s has no interval", name)
+			continue
+		}
+
+		task := &maintenanceTask{
+			name:     name,
+			command:  taskConfig.Command,
+			interval: taskConfig.Interval.Duration,
+			jitter:   taskConfig.Jitter.Duration,
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks
+}
+
+func newMaintenanceScheduler(tasks []*maintenanceTask) *maintenanceScheduler {
+	scheduler := &maintenanceScheduler{
+		random:  rand.New(rand.NewSource(time.Now().UnixNano())),
+		results: make(chan maintenanceTaskRun),
+		tasks:   tasks,
+	}
+
+	now := time.Now()
+	for _, task := range scheduler.tasks {
+		logrus.Debugf("Scheduling maintenance task %s every %s with a jitter of %s",
+			task.name,
+			task.interval,
+			task.jitter)
+
+		task.next = now.Add(scheduler.randomDuration(task.jitter))
+	}
+
+	scheduler.reset()
+	return scheduler
+}
+
+// C returns the channel on which the scheduler's timer fires. It's nil if
+// there are no tasks, so that selecting on it blocks forever.
+func (scheduler *maintenanceScheduler) C() <-chan time.Time {
+	if scheduler.timer == nil {
+		return nil
+	}
+
+	return scheduler.timer.C
+}
+
+// finish records that a task is no longer running.
+func (scheduler *maintenanceScheduler) finish(run maintenanceTaskRun) {
+	for _, task := range scheduler.tasks {
+		if task.name == run.Task {
+			task.running = false
+			break
+		}
+	}
+
+	scheduler.reset()
+}
+
+// randomDuration returns a random duration that's less than max.
+func (scheduler *maintenanceScheduler) randomDuration(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+
+	return time.Duration(scheduler.random.Int63n(int64(max)))
+}
+
+// reset arms the timer for the earliest task that's not running.
+func (scheduler *maintenanceScheduler) reset() {
+	var next time.Time
+
+	for _, task := range scheduler.tasks {
+		if task.running {
+			continue
+		}
+
+		if next.IsZero() || task.next.Before(next) {
+			next = task.next
+		}
+	}
+
+	if scheduler.timer != nil {
+		scheduler.timer.Stop()
+		scheduler.timer = nil
+	}
+
+	if next.IsZero() {
+		return
+	}
+
+	scheduler.timer = time.NewTimer(time.Until(next))
+}
+
+// runDue starts the tasks that are due in the background.
+func (scheduler *maintenanceScheduler) runDue() {
+	now := time.Now()
+
+	for _, task := range scheduler.tasks {
+		if task.running || task.next.After(now) {
+			continue
+		}
+
+		task.running = true
+		task.next = now.Add(task.interval + scheduler.randomDuration(task.jitter))
+
+		logrus.Debugf("Running maintenance task %s", task.name)
+		go runMaintenanceTask(task.name, task.command, scheduler.results)
+	}
+
+	scheduler.reset()
+}
+
+func (scheduler *maintenanceScheduler) stop() {
+	if scheduler.timer != nil {
+		scheduler.timer.Stop()
+	}
+}
+
+// recordMaintenanceTaskRun appends run to the history of maintenance tasks,
+// keeping only the most recent ones.
+func (status *initContainerStatus) recordMaintenanceTaskRun(run maintenanceTaskRun) {
+	status.Maintenance = append(status.Maintenance, run)
+
+	if excess := len(status.Maintenance) - maintenanceHistoryLength; excess > 0 {
+		status.Maintenance = status.Maintenance[excess:]
+	}
+
+	status.write()
+}
+
+func runMaintenanceTask(name string, command []string, results chan<- maintenanceTaskRun) {
+	run := maintenanceTaskRun{
+		Task:    name,
+		Started: time.Now(),
+	}
+
+	err := shell.Run(command[0], nil, nil, nil, command[1:]...)
+	run.Duration = time.Since(run.Started)
+
+	if err != nil {
+		logrus.Warnf("Failed to run maintenance task %s: %v", name, err)
+		run.Result = initContainerPhaseFailed
+		run.Error = err.Error()
+	} else {
+		logrus.Debugf("Finished maintenance task %s", name)
+		run.Result = initContainerPhaseOk
+	}
+
+	results <- run
+}
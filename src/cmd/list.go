@@ -31,10 +31,13 @@ import (
 )
 
 type toolboxImage struct {
-	ID      string
-	Names   []string
-	Created string
-	Labels  map[string]string
+	ID          string
+	Names       []string
+	Created     string
+	Digest      string
+	RepoDigests []string
+	Labels      map[string]string
+	Size        int64
 }
 
 type toolboxContainer struct {
@@ -43,11 +46,13 @@ type toolboxContainer struct {
 	Status  string
 	Created string
 	Image   string
+	ImageID string
 	Labels  map[string]string
 }
 
 var (
 	listFlags struct {
+		digests        bool
 		onlyContainers bool
 		onlyImages     bool
 	}
@@ -74,6 +79,11 @@ func init() {
 		false,
 		"List only toolbox containers, not images")
 
+	flags.BoolVar(&listFlags.digests,
+		"digests",
+		false,
+		"Show the digests of images, and of the images that containers are pinned to")
+
 	flags.BoolVarP(&listFlags.onlyImages,
 		"images",
 		"i",
@@ -124,7 +134,16 @@ func list(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	listOutput(images, containers)
+	var currentContainer string
+
+	if lsContainers {
+		currentContainer, _, err = getContainerForDirectory(workingDirectory)
+		if err != nil {
+			logrus.Debugf("Finding the container for the current directory failed: %s", err)
+		}
+	}
+
+	listOutput(images, containers, listFlags.digests, currentContainer)
 	return nil
 }
 
@@ -233,10 +252,18 @@ func getImages() ([]toolboxImage, error) {
 	return toolboxImages, nil
 }
 
-func listOutput(images []toolboxImage, containers []toolboxContainer) {
+// listOutput shows the images and containers. If currentContainer is set, a
+// column marks it as the container used in the current directory.
+func listOutput(images []toolboxImage, containers []toolboxContainer, showDigests bool, currentContainer string) {
 	if len(images) != 0 {
 		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintf(writer, "%s\t%s\t%s\n", "IMAGE ID", "IMAGE NAME", "CREATED")
+		fmt.Fprintf(writer, "%s\t%s\t%s", "IMAGE ID", "IMAGE NAME", "CREATED")
+
+		if showDigests {
+			fmt.Fprintf(writer, "\t%s", "DIGEST")
+		}
+
+		fmt.Fprintf(writer, "\n")
 
 		for _, image := range images {
 			imageName := "<none>"
@@ -244,10 +271,16 @@ func listOutput(images []toolboxImage, containers []toolboxContainer) {
 				imageName = image.Names[0]
 			}
 
-			fmt.Fprintf(writer, "%s\t%s\t%s\n",
+			fmt.Fprintf(writer, "%s\t%s\t%s",
 				utils.ShortID(image.ID),
 				imageName,
 				image.Created)
+
+			if showDigests {
+				fmt.Fprintf(writer, "\t%s", listOutputDigest(image.Digest))
+			}
+
+			fmt.Fprintf(writer, "\n")
 		}
 
 		writer.Flush()
@@ -277,6 +310,14 @@ func listOutput(images []toolboxImage, containers []toolboxContainer) {
 			"STATUS",
 			"IMAGE NAME")
 
+		if showDigests {
+			fmt.Fprintf(writer, "\t%s", "PINNED DIGEST")
+		}
+
+		if currentContainer != "" {
+			fmt.Fprintf(writer, "\t%s", "CURRENT")
+		}
+
 		if isatty.IsTerminal(stdoutFd) {
 			fmt.Fprintf(writer, "%s", resetColor)
 		}
@@ -307,6 +348,19 @@ func listOutput(images []toolboxImage, containers []toolboxContainer) {
 				container.Status,
 				container.Image)
 
+			if showDigests {
+				fmt.Fprintf(writer, "\t%s", listOutputDigest(container.Labels[toolboxDigestLabel]))
+			}
+
+			if currentContainer != "" {
+				current := ""
+				if container.Names[0] == currentContainer {
+					current = "*"
+				}
+
+				fmt.Fprintf(writer, "\t%s", current)
+			}
+
 			if isatty.IsTerminal(stdoutFd) {
 				fmt.Fprintf(writer, "%s", resetColor)
 			}
@@ -318,12 +372,23 @@ func listOutput(images []toolboxImage, containers []toolboxContainer) {
 	}
 }
 
+func listOutputDigest(digest string) string {
+	if digest == "" {
+		return "<none>"
+	}
+
+	return digest
+}
+
 func (i *toolboxImage) UnmarshalJSON(data []byte) error {
 	var raw struct {
-		ID      string
-		Names   []string
-		Created interface{}
-		Labels  map[string]string
+		ID          string
+		Names       []string
+		Created     interface{}
+		Digest      string
+		RepoDigests []string
+		Labels      map[string]string
+		Size        float64
 	}
 
 	if err := json.Unmarshal(data, &raw); err != nil {
@@ -342,7 +407,10 @@ func (i *toolboxImage) UnmarshalJSON(data []byte) error {
 		i.Created = utils.HumanDuration(int64(value))
 	}
 
+	i.Digest = raw.Digest
+	i.RepoDigests = raw.RepoDigests
 	i.Labels = raw.Labels
+	i.Size = int64(raw.Size)
 
 	return nil
 }
@@ -355,6 +423,7 @@ func (c *toolboxContainer) UnmarshalJSON(data []byte) error {
 		State   interface{}
 		Created interface{}
 		Image   string
+		ImageID string
 		Labels  map[string]string
 	}
 
@@ -398,6 +467,7 @@ func (c *toolboxContainer) UnmarshalJSON(data []byte) error {
 		c.Created = utils.HumanDuration(int64(value))
 	}
 	c.Image = raw.Image
+	c.ImageID = raw.ImageID
 	c.Labels = raw.Labels
 
 	return nil
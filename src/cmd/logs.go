@@ -0,0 +1,236 @@
+/*
+ * Copyright © 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logsFlags struct {
+		follow bool
+	}
+)
+
+var logsCmd = &cobra.Command{
+	Use:         "logs",
+	Short:       "Show how a toolbox container was initialized",
+	Args:        cobra.MaximumNArgs(1),
+	RunE:        logs,
+	Annotations: map[string]string{completionAnnotation: completionContainers},
+}
+
+func init() {
+	flags := logsCmd.Flags()
+
+	flags.BoolVarP(&logsFlags.follow,
+		"follow",
+		"f",
+		false,
+		"Keep showing the output of the toolbox container's entry point as it's written")
+
+	logsCmd.SetHelpFunc(logsHelp)
+	rootCmd.AddCommand(logsCmd)
+}
+
+func logs(cmd *cobra.Command, args []string) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	var container string
+
+	if len(args) != 0 {
+		container = args[0]
+
+		if !utils.IsContainerNameValid(container) {
+			var builder strings.Builder
+			fmt.Fprintf(&builder, "invalid argument for 'CONTAINER'\n")
+			fmt.Fprintf(&builder, "Container names must match '%s'\n", utils.ContainerNameRegexp)
+			fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+			errMsg := builder.String()
+			return errors.New(errMsg)
+		}
+	}
+
+	container, _, _, err := utils.ResolveContainerAndImageNames(container, "", "", "")
+	if err != nil {
+		return err
+	}
+
+	if _, err := podman.IsToolboxContainer(container); err != nil {
+		err := utils.CreateErrorContainerNotFound(container, executableBase)
+		return err
+	}
+
+	_, entryPointPID, err := getEntryPointAndPID(container)
+	if err != nil {
+		return err
+	}
+
+	toolboxRuntimeDirectory, err := utils.GetRuntimeDirectory(currentUser)
+	if err != nil {
+		return err
+	}
+
+	var statusPath string
+
+	if entryPointPID > 0 {
+		statusPath = fmt.Sprintf("%s/container-status-%d.json", toolboxRuntimeDirectory, entryPointPID)
+	} else {
+		fmt.Printf("Container %s is not running.\n", container)
+
+		// A container that failed to initialize has stopped, so the
+		// status kept under its ID is the only way to tell why
+		if containerID, err := getContainerID(container); err != nil {
+			logrus.Debugf("Reading last initialization status of container %s failed: %s", container, err)
+		} else {
+			statusPath = getInitContainerLastStatusPath(toolboxRuntimeDirectory, containerID)
+		}
+	}
+
+	if statusPath != "" {
+		status, err := readInitContainerStatus(statusPath)
+		if err != nil {
+			logrus.Debugf("Reading initialization status of container %s failed: %s", container, err)
+
+			if entryPointPID > 0 {
+				fmt.Printf("Initialization status of container %s is unavailable.\n", container)
+			}
+		} else {
+			logsOutputStatus(container, status, entryPointPID > 0)
+		}
+	}
+
+	fmt.Printf("\nOutput of the entry point:\n")
+
+	if err := podman.Logs(container, logsFlags.follow, os.Stdout, os.Stdout); err != nil {
+		return fmt.Errorf("failed to get the logs of container %s", container)
+	}
+
+	return nil
+}
+
+func logsHelp(cmd *cobra.Command, args []string) {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			fmt.Fprintf(os.Stderr, "Error: this is not a toolbox container\n")
+			return
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return
+		}
+
+		return
+	}
+
+	if err := utils.ShowManual("toolbox-logs"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return
+	}
+}
+
+func getContainerID(container string) (string, error) {
+	info, err := podman.Inspect("container", container)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect container %s", container)
+	}
+
+	containerID, ok := info["Id"].(string)
+	if !ok || containerID == "" {
+		return "", fmt.Errorf("failed to inspect the ID of container %s", container)
+	}
+
+	return containerID, nil
+}
+
+func logsOutputStatus(container string, status *initContainerStatus, running bool) {
+	if running {
+		fmt.Printf("Container %s is %s.\n\n", container, status.State)
+	} else {
+		fmt.Printf("Its last recorded state is %s.\n\n", status.State)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "%s\t%s\t%s\n", "PHASE", "RESULT", "DURATION")
+
+	for _, phase := range status.Phases {
+		duration := phase.Duration.Round(time.Millisecond).String()
+		if phase.Result == initContainerPhaseRunning {
+			duration = "-"
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", phase.Name, phase.Result, duration)
+	}
+
+	writer.Flush()
+
+	for _, phase := range status.Phases {
+		if phase.Error == "" {
+			continue
+		}
+
+		fmt.Printf("\nError: %s failed: %s\n", phase.Name, phase.Error)
+	}
+
+	if len(status.Maintenance) == 0 {
+		return
+	}
+
+	fmt.Printf("\n")
+
+	writer = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", "TASK", "STARTED", "RESULT", "DURATION")
+
+	for _, run := range status.Maintenance {
+		started := run.Started.Format(time.RFC3339)
+		duration := run.Duration.Round(time.Millisecond).String()
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", run.Task, started, run.Result, duration)
+	}
+
+	writer.Flush()
+
+	for _, run := range status.Maintenance {
+		if run.Error == "" {
+			continue
+		}
+
+		started := run.Started.Format(time.RFC3339)
+		fmt.Printf("\nError: %s at %s failed: %s\n", run.Task, started, run.Error)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/containers/toolbox/pkg/manual"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestManualsDocumentFlags checks that the flags of every command are
+// documented in its manual, which is also built into the binary.
+func TestManualsDocumentFlags(t *testing.T) {
+	var visit func(command *cobra.Command, name string)
+
+	visit = func(command *cobra.Command, name string) {
+		t.Run(name, func(t *testing.T) {
+			source, ok := manual.Get(name)
+			if !assert.True(t, ok, "manual %s is missing", name) {
+				return
+			}
+
+			lines := strings.Split(source, "\n")
+
+			command.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+				if flag.Hidden {
+					return
+				}
+
+				// Options are documented in paragraphs starting like
+				// '**--distro** DISTRO, **-d** DISTRO' or '**--follow, -f**'
+				var heading string
+				for _, line := range lines {
+					if strings.HasPrefix(line, "**--"+flag.Name+"*") ||
+						strings.HasPrefix(line, "**--"+flag.Name+",") ||
+						strings.HasPrefix(line, "**--"+flag.Name+"=") {
+						heading = line
+						break
+					}
+				}
+
+				if !assert.NotEmpty(t, heading, "manual %s doesn't document --%s", name, flag.Name) {
+					return
+				}
+
+				if flag.Shorthand != "" {
+					assert.Regexp(t,
+						"[ *]-"+flag.Shorthand+"\\b",
+						heading,
+						"manual %s doesn't document -%s",
+						name,
+						flag.Shorthand)
+				}
+			})
+		})
+
+		for _, subCommand := range command.Commands() {
+			if subCommand == completeCmd {
+				continue
+			}
+
+			visit(subCommand, name+"-"+subCommand.Name())
+		}
+	}
+
+	visit(rootCmd, "toolbox")
+}
@@ -0,0 +1,247 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// pluginPrefix is the prefix of the executables that provide plugin commands,
+// like 'toolbox-foo' for 'toolbox foo'
+const pluginPrefix = "toolbox-"
+
+type plugin struct {
+	Name string
+	Path string
+}
+
+// addPluginCommand adds a command for the plugin named by the first
+// non-option argument in args, if it's not a built-in command. The options
+// before it are parsed as the global options of Toolbox, and everything after
+// it is given to the plugin.
+func addPluginCommand(args []string) error {
+	index := getCommandIndex(args)
+	if index == -1 {
+		return nil
+	}
+
+	name := args[index]
+	if isBuiltInCommand(name) {
+		return nil
+	}
+
+	var path string
+
+	// Plugins are run on the host, so inside a toolbox container they are
+	// forwarded without looking for them
+	if !utils.IsInsideContainer() {
+		var err error
+		path, err = getPluginPath(name)
+		if err != nil {
+			return err
+		}
+
+		if path == "" {
+			return nil
+		}
+	}
+
+	// Unknown options, like --help, are left to cobra
+	if err := rootCmd.PersistentFlags().Parse(args[:index]); err != nil {
+		logrus.Debugf("Parsing the options before plugin %s failed: %s", name, err)
+		return nil
+	}
+
+	pluginArgs := args[index+1:]
+
+	pluginCmd := &cobra.Command{
+		Use:                name,
+		Short:              "Run a plugin",
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPlugin(path, pluginArgs)
+		},
+	}
+
+	rootCmd.AddCommand(pluginCmd)
+	return nil
+}
+
+// getPluginDirectories returns the directories that are searched for plugins
+// in order of precedence: ~/.local/libexec/toolbox followed by $PATH. Empty
+// and relative entries in $PATH are skipped, so that a plugin is never run
+// from the current directory.
+func getPluginDirectories() []string {
+	directories := []string{filepath.Join(currentUser.HomeDir, ".local", "libexec", "toolbox")}
+
+	for _, directory := range filepath.SplitList(os.Getenv("PATH")) {
+		if !filepath.IsAbs(directory) {
+			logrus.Debugf("Not looking for plugins in relative path %q from $PATH", directory)
+			continue
+		}
+
+		directories = append(directories, directory)
+	}
+
+	return directories
+}
+
+// getPluginPath returns the path to the executable of the plugin called name,
+// or an empty string if there's none.
+func getPluginPath(name string) (string, error) {
+	for _, directory := range getPluginDirectories() {
+		path := filepath.Join(directory, pluginPrefix+name)
+		if isExecutableFile(path) {
+			logrus.Debugf("Found plugin %s at %s", name, path)
+			return path, nil
+		}
+	}
+
+	logrus.Debugf("Plugin %s not found", name)
+	return "", nil
+}
+
+// getPlugins returns the plugins that can be run, sorted by name. Plugins
+// that are shadowed by a built-in command or by a plugin in a directory with a
+// higher precedence are left out.
+func getPlugins() []plugin {
+	var plugins []plugin
+	seen := make(map[string]bool)
+
+	for _, directory := range getPluginDirectories() {
+		files, err := ioutil.ReadDir(directory)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				logrus.Debugf("Looking for plugins in %s failed: %s", directory, err)
+			}
+
+			continue
+		}
+
+		for _, file := range files {
+			fileName := file.Name()
+			if !strings.HasPrefix(fileName, pluginPrefix) {
+				continue
+			}
+
+			name := strings.TrimPrefix(fileName, pluginPrefix)
+			if name == "" || seen[name] {
+				continue
+			}
+
+			path := filepath.Join(directory, fileName)
+			if !isExecutableFile(path) {
+				continue
+			}
+
+			seen[name] = true
+
+			if isBuiltInCommand(name) {
+				logrus.Debugf("Plugin %s at %s is shadowed by a built-in command", name, path)
+				continue
+			}
+
+			plugins = append(plugins, plugin{Name: name, Path: path})
+		}
+	}
+
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+
+	return plugins
+}
+
+func isExecutableFile(path string) bool {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	return fileInfo.Mode().IsRegular() && fileInfo.Mode().Perm()&0111 != 0
+}
+
+// getPluginsHelp lists the plugins for the help of the root command, or
+// returns an empty string if there are none.
+func getPluginsHelp() string {
+	plugins := getPlugins()
+	if len(plugins) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "\nPlugins available as commands:\n")
+
+	for _, plugin := range plugins {
+		fmt.Fprintf(&builder, "  %-20s %s\n", plugin.Name, plugin.Path)
+	}
+
+	pluginsHelp := builder.String()
+	return pluginsHelp
+}
+
+func runPlugin(path string, args []string) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	container, image, release, err := utils.ResolveContainerAndImageNames("", "", "", "")
+	if err != nil {
+		return err
+	}
+
+	container, err = resolveDefaultContainer(container, false)
+	if err != nil {
+		return err
+	}
+
+	env := os.Environ()
+	env = append(env, []string{
+		"TOOLBOX_CONTAINER=" + container,
+		"TOOLBOX_IMAGE=" + image,
+		"TOOLBOX_RELEASE=" + release,
+	}...)
+
+	pluginArgs := append([]string{path}, args...)
+
+	logrus.Debugf("Running plugin %s", path)
+
+	if err := syscall.Exec(path, pluginArgs, env); err != nil {
+		return fmt.Errorf("failed to run plugin %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,508 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/docker/go-units"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneFlags struct {
+		dryRun    bool
+		olderThan string
+	}
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove unused toolbox images and stale toolbox containers",
+	RunE:  prune,
+}
+
+func init() {
+	flags := pruneCmd.Flags()
+
+	flags.BoolVar(&pruneFlags.dryRun,
+		"dry-run",
+		false,
+		"Show what would be removed without removing anything")
+
+	flags.StringVar(&pruneFlags.olderThan,
+		"older-than",
+		"",
+		"Remove toolbox containers that weren't entered for this long (eg., 30d or 12h)")
+
+	pruneCmd.SetHelpFunc(pruneHelp)
+	rootCmd.AddCommand(pruneCmd)
+}
+
+func prune(cmd *cobra.Command, args []string) error {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			return errors.New("this is not a toolbox container")
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	var olderThan time.Duration
+
+	if pruneFlags.olderThan != "" {
+		var err error
+		olderThan, err = parsePruneDuration(pruneFlags.olderThan)
+		if err != nil {
+			var builder strings.Builder
+			fmt.Fprintf(&builder, "invalid argument for '--older-than'\n")
+			fmt.Fprintf(&builder, "Durations must look like 30d, 12h or 90m\n")
+			fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+			errMsg := builder.String()
+			return errors.New(errMsg)
+		}
+	}
+
+	containers, err := getContainers()
+	if err != nil {
+		return err
+	}
+
+	var reclaimed int64
+	var removedContainers int
+
+	entryPointsKnown := true
+
+	entryPointPIDs := make(map[int]bool)
+	keptContainerIDs := make(map[string]bool)
+	keptContainers := make(map[string]bool)
+	prunedContainers := make(map[string]bool)
+
+	for _, container := range containers {
+		name := container.Names[0]
+		keptContainerIDs[container.ID] = true
+		keptContainers[name] = true
+
+		_, entryPointPID, err := getEntryPointAndPID(name)
+		if err != nil {
+			logrus.Debugf("Pruning: %s", err)
+			entryPointsKnown = false
+			continue
+		}
+
+		if entryPointPID > 0 {
+			entryPointPIDs[entryPointPID] = true
+			continue
+		}
+
+		if olderThan == 0 {
+			continue
+		}
+
+		lastUsed, err := getContainerLastUsed(name)
+		if err != nil {
+			logrus.Debugf("Pruning: failed to find out when container %s was last used: %s", name, err)
+			continue
+		}
+
+		if time.Since(lastUsed) < olderThan {
+			continue
+		}
+
+		size := getContainerSize(container.ID)
+		lastUsedString := utils.HumanDuration(lastUsed.Unix())
+
+		if pruneFlags.dryRun {
+			fmt.Printf("Would remove container %s (last used %s)\n", name, lastUsedString)
+		} else {
+			if err := podman.RemoveContainer(name, false); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				continue
+			}
+
+			fmt.Printf("Removed container %s (last used %s)\n", name, lastUsedString)
+		}
+
+		delete(keptContainerIDs, container.ID)
+		delete(keptContainers, name)
+		prunedContainers[name] = true
+		removedContainers++
+		reclaimed += size
+	}
+
+	usedImages, err := getUsedImages(prunedContainers)
+	if err != nil {
+		return err
+	}
+
+	images, err := getImages()
+	if err != nil {
+		return err
+	}
+
+	var removedImages int
+
+	for _, image := range images {
+		if usedImages[image.ID] {
+			continue
+		}
+
+		imageName := utils.ShortID(image.ID)
+		if len(image.Names) != 0 {
+			imageName = image.Names[0]
+		}
+
+		if pruneFlags.dryRun {
+			fmt.Printf("Would remove image %s\n", imageName)
+		} else {
+			if err := podman.RemoveImage(image.ID, false); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				continue
+			}
+
+			fmt.Printf("Removed image %s\n", imageName)
+		}
+
+		removedImages++
+		reclaimed += image.Size
+	}
+
+	// Without the PIDs of all entry points, the stamps of a running
+	// container might be mistaken for stale ones
+	if !entryPointsKnown {
+		logrus.Debug("Pruning: not removing initialization stamps, because some entry points are unknown")
+		entryPointPIDs = nil
+	}
+
+	removedStamps := pruneRuntimeDirectory(entryPointPIDs, keptContainerIDs)
+	removedStamps += pruneLastUsedStamps(keptContainers)
+
+	verb := "Removed"
+	if pruneFlags.dryRun {
+		verb = "Would remove"
+	}
+
+	fmt.Printf("%s %d containers, %d images and %d stale files\n",
+		verb,
+		removedContainers,
+		removedImages,
+		removedStamps)
+
+	fmt.Printf("Total reclaimed space: %s\n", units.HumanSize(float64(reclaimed)))
+	return nil
+}
+
+func pruneHelp(cmd *cobra.Command, args []string) {
+	if utils.IsInsideContainer() {
+		if !utils.IsInsideToolboxContainer() {
+			fmt.Fprintf(os.Stderr, "Error: this is not a toolbox container\n")
+			return
+		}
+
+		if _, err := utils.ForwardToHost(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return
+		}
+
+		return
+	}
+
+	if err := utils.ShowManual("toolbox-prune"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return
+	}
+}
+
+// getContainerLastUsed returns when a toolbox container was last entered, or
+// when it was created if it was never entered since Toolbox started keeping
+// track.
+func getContainerLastUsed(container string) (time.Time, error) {
+	if stampDir, err := getLastUsedStampDir(); err == nil {
+		stampPath := stampDir + "/" + container
+		if fileInfo, err := os.Stat(stampPath); err == nil {
+			return fileInfo.ModTime(), nil
+		}
+	}
+
+	info, err := podman.Inspect("container", container)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to inspect container %s", container)
+	}
+
+	created, _ := info["Created"].(string)
+	createdTime, err := time.Parse(time.RFC3339Nano, created)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse the creation time of container %s", container)
+	}
+
+	return createdTime, nil
+}
+
+// getContainerSize returns the size of a container's writable layer, or 0 if
+// it can't be found out.
+func getContainerSize(id string) int64 {
+	containers, err := podman.GetContainers("--all", "--size", "--filter", "id="+id)
+	if err != nil || len(containers) != 1 {
+		logrus.Debugf("Pruning: failed to get the size of container %s", id)
+		return 0
+	}
+
+	size, _ := containers[0]["Size"].(map[string]interface{})
+	rwSize, _ := size["rwSize"].(float64)
+	return int64(rwSize)
+}
+
+func getLastUsedStampDir() (string, error) {
+	stateDirectory, err := utils.GetStateDirectory()
+	if err != nil {
+		return "", err
+	}
+
+	stampDir := stateDirectory + "/last-used"
+	return stampDir, nil
+}
+
+// getUsedImages returns the IDs of the images used by any container, not only
+// toolbox containers, leaving out the containers that were pruned.
+func getUsedImages(prunedContainers map[string]bool) (map[string]bool, error) {
+	containers, err := podman.GetContainers("--all")
+	if err != nil {
+		logrus.Debugf("Fetching all containers failed: %s", err)
+		return nil, errors.New("failed to get containers")
+	}
+
+	usedImages := make(map[string]bool)
+
+	for _, container := range containers {
+		var c toolboxContainer
+
+		containerJSON, err := json.Marshal(container)
+		if err != nil {
+			logrus.Errorf("failed to marshal container: %v", err)
+			continue
+		}
+
+		if err := c.UnmarshalJSON(containerJSON); err != nil {
+			logrus.Errorf("failed to unmarshal container: %v", err)
+			continue
+		}
+
+		if len(c.Names) != 0 && prunedContainers[c.Names[0]] {
+			continue
+		}
+
+		usedImages[c.ImageID] = true
+	}
+
+	return usedImages, nil
+}
+
+// parsePruneDuration parses durations like 30d in addition to those
+// understood by time.ParseDuration.
+func parsePruneDuration(value string) (time.Duration, error) {
+	if days := strings.TrimSuffix(value, "d"); days != value {
+		daysInt, err := strconv.Atoi(days)
+		if err != nil || daysInt <= 0 {
+			return 0, fmt.Errorf("invalid duration %s", value)
+		}
+
+		return time.Duration(daysInt) * 24 * time.Hour, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if duration <= 0 {
+		return 0, fmt.Errorf("invalid duration %s", value)
+	}
+
+	return duration, nil
+}
+
+// pruneLastUsedStamps removes the stamps recording when containers were last
+// used, for containers that don't exist anymore.
+func pruneLastUsedStamps(keptContainers map[string]bool) int {
+	stampDir, err := getLastUsedStampDir()
+	if err != nil {
+		logrus.Debugf("Pruning: %s", err)
+		return 0
+	}
+
+	fileInfos, err := ioutil.ReadDir(stampDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logrus.Debugf("Pruning: failed to read %s: %s", stampDir, err)
+		}
+
+		return 0
+	}
+
+	var removed int
+
+	for _, fileInfo := range fileInfos {
+		if keptContainers[fileInfo.Name()] {
+			continue
+		}
+
+		if pruneRemoveFile(filepath.Join(stampDir, fileInfo.Name())) {
+			removed++
+		}
+	}
+
+	return removed
+}
+
+func pruneRemoveFile(path string) bool {
+	if pruneFlags.dryRun {
+		logrus.Debugf("Would remove stale file %s", path)
+		return true
+	}
+
+	logrus.Debugf("Removing stale file %s", path)
+
+	if err := os.Remove(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to remove %s: %s\n", path, err)
+		return false
+	}
+
+	return true
+}
+
+// pruneRuntimeDirectory removes the initialization stamps and status files
+// left behind by entry points that aren't running anymore, and the last
+// initialization status of containers that don't exist anymore. If
+// entryPointPIDs is nil, the former are left alone.
+func pruneRuntimeDirectory(entryPointPIDs map[int]bool, keptContainerIDs map[string]bool) int {
+	toolboxRuntimeDirectory, err := utils.GetRuntimeDirectory(currentUser)
+	if err != nil {
+		logrus.Debugf("Pruning: %s", err)
+		return 0
+	}
+
+	fileInfos, err := ioutil.ReadDir(toolboxRuntimeDirectory)
+	if err != nil {
+		logrus.Debugf("Pruning: failed to read %s: %s", toolboxRuntimeDirectory, err)
+		return 0
+	}
+
+	var removed int
+
+	for _, fileInfo := range fileInfos {
+		name := fileInfo.Name()
+
+		// Entry points that started after the containers were listed
+		// mustn't lose their files
+		if time.Since(fileInfo.ModTime()) < time.Minute {
+			continue
+		}
+
+		if strings.HasPrefix(name, "container-last-status-") && strings.HasSuffix(name, ".json") {
+			containerID := strings.TrimSuffix(strings.TrimPrefix(name, "container-last-status-"), ".json")
+			if keptContainerIDs[containerID] {
+				continue
+			}
+
+			if pruneRemoveFile(filepath.Join(toolboxRuntimeDirectory, name)) {
+				removed++
+			}
+
+			continue
+		}
+
+		if entryPointPIDs == nil {
+			continue
+		}
+
+		var pidString string
+
+		if strings.HasPrefix(name, "container-initialized-") {
+			pidString = strings.TrimPrefix(name, "container-initialized-")
+		} else if strings.HasPrefix(name, "container-status-") && strings.HasSuffix(name, ".json") {
+			pidString = strings.TrimSuffix(strings.TrimPrefix(name, "container-status-"), ".json")
+		} else {
+			continue
+		}
+
+		pid, err := strconv.Atoi(pidString)
+		if err != nil || entryPointPIDs[pid] || isProcessRunning(pid) {
+			continue
+		}
+
+		if pruneRemoveFile(filepath.Join(toolboxRuntimeDirectory, name)) {
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// isProcessRunning checks if a process with the given PID exists. A process
+// owned by someone else counts as running.
+func isProcessRunning(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// recordContainerLastUsed updates the stamp that 'prune' uses to find toolbox
+// containers that weren't entered for a while.
+func recordContainerLastUsed(container string) {
+	stampDir, err := getLastUsedStampDir()
+	if err != nil {
+		logrus.Debugf("Recording use of container %s: %s", container, err)
+		return
+	}
+
+	if err := os.MkdirAll(stampDir, 0700); err != nil {
+		logrus.Debugf("Recording use of container %s: failed to create directory %s: %s",
+			container,
+			stampDir,
+			err)
+		return
+	}
+
+	stampPath := stampDir + "/" + container
+	if err := ioutil.WriteFile(stampPath, nil, 0644); err != nil {
+		logrus.Debugf("Recording use of container %s: failed to write stamp file %s: %s",
+			container,
+			stampPath,
+			err)
+		return
+	}
+}
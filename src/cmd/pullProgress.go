@@ -0,0 +1,152 @@
+/*
+ * Copyright © 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/docker/go-units"
+)
+
+const (
+	pullProgressBarWidth = 30
+
+	// pullProgressLineStep is the percentage of progress between two lines
+	// of output when not on a terminal
+	pullProgressLineStep = 10
+
+	// pullProgressRedrawInterval limits how often the progress bar is
+	// drawn on a terminal
+	pullProgressRedrawInterval = 100 * time.Millisecond
+)
+
+// pullProgressBar shows the progress of pulling an image. On a terminal, it
+// draws a progress bar with the amount of data downloaded and an estimate of
+// the time left. Otherwise it prints a line every few percent.
+type pullProgressBar struct {
+	drawn       time.Time
+	image       string
+	lastPercent int
+	started     time.Time
+	terminal    bool
+	writer      io.Writer
+}
+
+func newPullProgressBar(image string, writer io.Writer, terminal bool) *pullProgressBar {
+	bar := &pullProgressBar{
+		image:       image,
+		lastPercent: -1,
+		started:     time.Now(),
+		terminal:    terminal,
+		writer:      writer,
+	}
+
+	return bar
+}
+
+// finish ends the line of the progress bar on a terminal.
+func (bar *pullProgressBar) finish() {
+	if bar.terminal && !bar.drawn.IsZero() {
+		fmt.Fprintf(bar.writer, "\n")
+	}
+}
+
+func (bar *pullProgressBar) update(progress podman.PullProgress) {
+	if bar.terminal {
+		bar.draw(progress)
+	} else {
+		bar.printLine(progress)
+	}
+}
+
+func (bar *pullProgressBar) draw(progress podman.PullProgress) {
+	now := time.Now()
+	finished := progress.Blobs != 0 && progress.BlobsDone == progress.Blobs
+
+	if !finished && now.Sub(bar.drawn) < pullProgressRedrawInterval {
+		return
+	}
+
+	bar.drawn = now
+
+	var status string
+
+	if progress.Total == 0 {
+		status = fmt.Sprintf("%d/%d layers", progress.BlobsDone, progress.Blobs)
+	} else {
+		filled := int(int64(pullProgressBarWidth) * progress.Current / progress.Total)
+		if filled > pullProgressBarWidth {
+			filled = pullProgressBarWidth
+		}
+
+		status = fmt.Sprintf("[%s%s] %s / %s",
+			strings.Repeat("=", filled),
+			strings.Repeat(" ", pullProgressBarWidth-filled),
+			units.HumanSize(float64(progress.Current)),
+			units.HumanSize(float64(progress.Total)))
+
+		if eta, ok := bar.estimateTimeLeft(progress); ok && !finished {
+			status += fmt.Sprintf(", %s left", eta)
+		}
+	}
+
+	fmt.Fprintf(bar.writer, "\r\033[KPulling %s: %s", bar.image, status)
+}
+
+// estimateTimeLeft extrapolates from the average speed so far.
+func (bar *pullProgressBar) estimateTimeLeft(progress podman.PullProgress) (time.Duration, bool) {
+	if progress.Current <= 0 || progress.Total <= progress.Current {
+		return 0, false
+	}
+
+	elapsed := time.Since(bar.started)
+	left := float64(elapsed) * float64(progress.Total-progress.Current) / float64(progress.Current)
+
+	eta := time.Duration(left).Round(time.Second)
+	return eta, true
+}
+
+func (bar *pullProgressBar) printLine(progress podman.PullProgress) {
+	if progress.Total == 0 {
+		if bar.lastPercent == -1 {
+			fmt.Fprintf(bar.writer, "Pulling %s\n", bar.image)
+			bar.lastPercent = 0
+		}
+
+		return
+	}
+
+	percent := int(100 * progress.Current / progress.Total)
+	percent = percent - percent%pullProgressLineStep
+
+	if percent <= bar.lastPercent {
+		return
+	}
+
+	bar.lastPercent = percent
+
+	fmt.Fprintf(bar.writer,
+		"Pulling %s: %d%% (%s of %s)\n",
+		bar.image,
+		percent,
+		units.HumanSize(float64(progress.Current)),
+		units.HumanSize(float64(progress.Total)))
+}
@@ -27,9 +27,12 @@ import (
 	"strings"
 	"syscall"
 
+	"github.com/containers/toolbox/pkg/config"
+	"github.com/containers/toolbox/pkg/logging"
 	"github.com/containers/toolbox/pkg/podman"
 	"github.com/containers/toolbox/pkg/utils"
 	"github.com/containers/toolbox/pkg/version"
+	"github.com/docker/go-units"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -39,10 +42,22 @@ var (
 
 	currentUser *user.User
 
+	toolboxConfig *config.Config
+
 	executable string
 
 	executableBase string
 
+	// logFileDefaultMaxFiles is the number of rotated log files that are
+	// kept, unless configured otherwise
+	logFileDefaultMaxFiles = 3
+
+	// logFileDefaultMaxSize is the size above which the log file is
+	// rotated, unless configured otherwise
+	logFileDefaultMaxSize int64 = 10 * units.MiB
+
+	logFormats = []string{"json", "text"}
+
 	rootCmd = &cobra.Command{
 		Use:               "toolbox",
 		Short:             "Tool for containerized command line environments on Linux",
@@ -53,6 +68,8 @@ var (
 
 	rootFlags struct {
 		assumeYes bool
+		logFile   bool
+		logFormat string
 		logLevel  string
 		logPodman bool
 		verbose   int
@@ -67,6 +84,40 @@ func Execute() {
 		os.Exit(1)
 	}
 
+	// Aliases are expanded before the command line is parsed, so the
+	// configuration is loaded early for them. A broken configuration is
+	// only fatal for commands that aren't built-in, because they might be
+	// aliases, and otherwise it's left to preRun.
+	earlyConfig, err := config.Load()
+	if err != nil {
+		index := getCommandIndex(os.Args[1:])
+		if index != -1 {
+			if _, _, findErr := rootCmd.Find(os.Args[1+index : 2+index]); findErr != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				os.Exit(1)
+			}
+		}
+
+		earlyConfig = &config.Config{}
+	}
+
+	args, err := expandAlias(earlyConfig.Aliases, os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	if err := addPluginCommand(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	// The loggers are set up before the configuration is loaded again, so
+	// they need the early one for the [log] table
+	toolboxConfig = earlyConfig
+
+	rootCmd.SetArgs(args)
+
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
@@ -83,6 +134,16 @@ func init() {
 		false,
 		"Automatically answer yes for all questions")
 
+	persistentFlags.BoolVar(&rootFlags.logFile,
+		"log-file",
+		false,
+		"Append log messages to $XDG_STATE_HOME/toolbox/toolbox.log instead of the standard error")
+
+	persistentFlags.StringVar(&rootFlags.logFormat,
+		"log-format",
+		"text",
+		"Write log messages in the specified format: text or json")
+
 	persistentFlags.StringVar(&rootFlags.logLevel,
 		"log-level",
 		"error",
@@ -95,6 +156,9 @@ func init() {
 
 	persistentFlags.CountVarP(&rootFlags.verbose, "verbose", "v", "Set log-level to 'debug'")
 
+	markFlagCompletion(persistentFlags, "log-format", completionLogFormats)
+	markFlagCompletion(persistentFlags, "log-level", completionLogLevels)
+
 	rootCmd.SetHelpFunc(rootHelp)
 	rootCmd.SetUsageFunc(rootUsage)
 }
@@ -102,10 +166,26 @@ func init() {
 func preRun(cmd *cobra.Command, args []string) error {
 	cmd.Root().SilenceUsage = true
 
-	if err := setUpLoggers(); err != nil {
+	if err := setUpLoggers(cmd); err != nil {
 		return err
 	}
 
+	var err error
+	toolboxConfig, err = config.Load()
+	if err != nil {
+		if !canIgnoreConfigErrors(cmd) {
+			return err
+		}
+
+		logrus.Warnf("Ignoring the configuration: %s", err)
+		toolboxConfig = &config.Config{}
+	}
+
+	if err := utils.SetPreservedEnvironmentVariables(toolboxConfig.Environment.Allow,
+		toolboxConfig.Environment.Deny); err != nil {
+		return fmt.Errorf("failed to configure the environment: %w", err)
+	}
+
 	logrus.Debugf("Running as real user ID %s", currentUser.Uid)
 	logrus.Debugf("Resolved absolute path to the executable as %s", executable)
 
@@ -170,7 +250,12 @@ func rootHelp(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	if err := utils.ShowManual(manual); err != nil {
+	var pluginsHelp string
+	if manual == "toolbox" {
+		pluginsHelp = getPluginsHelp()
+	}
+
+	if err := utils.ShowManualFollowedBy(manual, pluginsHelp); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		return
 	}
@@ -226,6 +311,8 @@ func rootRun(cmd *cobra.Command, args []string) error {
 		image,
 		release,
 		command,
+		execOptions{},
+		toolboxConfig.Create.OnDemand,
 		emitEscapeSequence,
 		true,
 		false); err != nil {
@@ -241,6 +328,68 @@ func rootUsage(cmd *cobra.Command) error {
 	return err
 }
 
+// canIgnoreConfigErrors checks if cmd should work even if the configuration
+// can't be loaded. The entry point of toolbox containers mustn't fail because
+// of it, and neither should the help or the shell completion.
+func canIgnoreConfigErrors(cmd *cobra.Command) bool {
+	if !cmd.HasParent() || cmd.Parent() != cmd.Root() {
+		return false
+	}
+
+	switch cmd.Name() {
+	case "__complete", "completion", "help", "init-container":
+		return true
+	}
+
+	return false
+}
+
+// getCommandIndex returns the index of the first non-option argument in args,
+// skipping the values of the global options, or -1 if there's none.
+func getCommandIndex(args []string) int {
+	flags := rootCmd.PersistentFlags()
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			return -1
+		}
+
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return i
+		}
+
+		if strings.Contains(arg, "=") {
+			continue
+		}
+
+		var name string
+		if strings.HasPrefix(arg, "--") {
+			name = arg[2:]
+		} else {
+			name = arg[len(arg)-1:]
+		}
+
+		flag := flags.Lookup(name)
+		if flag == nil && len(name) == 1 {
+			flag = flags.ShorthandLookup(name)
+		}
+
+		if flag != nil && flag.NoOptDefVal == "" {
+			i++
+		}
+	}
+
+	return -1
+}
+
+// isBuiltInCommand checks if name is a command of Toolbox, as opposed to an
+// alias or a plugin.
+func isBuiltInCommand(name string) bool {
+	return completionFindCommand(rootCmd, name) != nil || name == "help"
+}
+
 func migrate() error {
 	logrus.Debug("Migrating to newer Podman")
 
@@ -389,11 +538,45 @@ func setUpGlobals() error {
 	return nil
 }
 
-func setUpLoggers() error {
+func setUpLoggers(cmd *cobra.Command) error {
+	logFormat := rootFlags.logFormat
+	if !cmd.Flags().Changed("log-format") && toolboxConfig.Log.Format != "" {
+		logFormat = toolboxConfig.Log.Format
+	}
+
+	logFile := rootFlags.logFile || toolboxConfig.Log.File
+
+	var formatter logrus.Formatter
+
+	switch logFormat {
+	case "json":
+		formatter = &logrus.JSONFormatter{}
+	case "text":
+		formatter = &logrus.TextFormatter{
+			DisableTimestamp: !logFile,
+			FullTimestamp:    logFile,
+		}
+	default:
+		return fmt.Errorf("failed to parse log-format: not a valid format: %s", logFormat)
+	}
+
+	logrus.SetFormatter(formatter)
+
+	// A problem with the log file mustn't prevent the command from
+	// running, so the log messages go to the standard error instead
 	logrus.SetOutput(os.Stderr)
-	logrus.SetFormatter(&logrus.TextFormatter{
-		DisableTimestamp: true,
-	})
+
+	if logFile {
+		if file, err := openLogFile(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: logging to the standard error: %s\n", err)
+		} else {
+			logrus.SetOutput(file)
+		}
+	}
+
+	if !cmd.Flags().Changed("log-level") && toolboxConfig.Log.Level != "" {
+		rootFlags.logLevel = toolboxConfig.Log.Level
+	}
 
 	if rootFlags.verbose > 0 {
 		rootFlags.logLevel = "debug"
@@ -417,6 +600,42 @@ func setUpLoggers() error {
 	return nil
 }
 
+// openLogFile opens the file used by --log-file, and rotates it if needed.
+func openLogFile() (*os.File, error) {
+	path, err := getLogFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	maxSize := logFileDefaultMaxSize
+	if toolboxConfig.Log.MaxSize.Bytes != 0 {
+		maxSize = toolboxConfig.Log.MaxSize.Bytes
+	}
+
+	maxFiles := logFileDefaultMaxFiles
+	if toolboxConfig.Log.MaxFiles != nil {
+		maxFiles = *toolboxConfig.Log.MaxFiles
+	}
+
+	file, err := logging.OpenFile(path, maxSize, maxFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// getLogFilePath returns the path of the file used by --log-file.
+func getLogFilePath() (string, error) {
+	stateDirectory, err := utils.GetStateDirectory()
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(stateDirectory, "toolbox.log")
+	return path, nil
+}
+
 func validateSubIDFile(path string) (bool, error) {
 	logrus.Debugf("Validating sub-ID file %s", path)
 
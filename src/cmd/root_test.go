@@ -0,0 +1,78 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCommandIndex(t *testing.T) {
+	testCases := []struct {
+		name  string
+		args  []string
+		index int
+	}{
+		{
+			name:  "No arguments",
+			args:  []string{},
+			index: -1,
+		},
+		{
+			name:  "Command",
+			args:  []string{"foo", "--bar"},
+			index: 0,
+		},
+		{
+			name:  "Option without a value",
+			args:  []string{"--assumeyes", "foo"},
+			index: 1,
+		},
+		{
+			name:  "Option with a separate value",
+			args:  []string{"--log-level", "debug", "foo"},
+			index: 2,
+		},
+		{
+			name:  "Option with an inline value",
+			args:  []string{"--log-level=debug", "foo"},
+			index: 1,
+		},
+		{
+			name:  "Short options",
+			args:  []string{"-vy", "foo"},
+			index: 1,
+		},
+		{
+			name:  "Only options",
+			args:  []string{"-v", "--log-podman"},
+			index: -1,
+		},
+		{
+			name:  "End of options",
+			args:  []string{"--", "foo"},
+			index: -1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.index, getCommandIndex(tc.args))
+		})
+	}
+}
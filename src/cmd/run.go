@@ -20,55 +20,123 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/containers/toolbox/pkg/config"
 	"github.com/containers/toolbox/pkg/podman"
 	"github.com/containers/toolbox/pkg/shell"
 	"github.com/containers/toolbox/pkg/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
 var (
 	runFlags struct {
+		cleanEnv  bool
 		container string
+		create    bool
 		distro    string
+		env       []string
+		envFile   []string
 		release   string
+		root      bool
+		workDir   string
 	}
 
 	runFallbackCommands = [][]string{{"/bin/bash", "-l"}}
 	runFallbackWorkDirs = []string{"" /* $HOME */}
 )
 
+// execOptions holds the options of 'enter' and 'run' that affect how a
+// command is run inside a toolbox container.
+type execOptions struct {
+	// cleanEnv forwards only a minimal set of environment variables,
+	// instead of all the preserved ones.
+	cleanEnv bool
+
+	// env holds KEY=VALUE assignments added to the preserved environment
+	// variables.
+	env []string
+
+	// root runs the command as root instead of the current user.
+	root bool
+
+	// workDir overrides the current working directory. An empty string
+	// means that the current working directory is used, if it's present in
+	// the container.
+	workDir string
+}
+
 var runCmd = &cobra.Command{
-	Use:   "run",
-	Short: "Run a command in an existing toolbox container",
-	RunE:  run,
+	Use:         "run",
+	Short:       "Run a command in an existing toolbox container",
+	RunE:        run,
+	Annotations: map[string]string{completionAnnotation: completionCommandLine},
 }
 
 func init() {
 	flags := runCmd.Flags()
 	flags.SetInterspersed(false)
 
+	flags.BoolVar(&runFlags.cleanEnv,
+		"clean-env",
+		false,
+		"Run command with a minimal environment instead of the host's")
+
 	flags.StringVarP(&runFlags.container,
 		"container",
 		"c",
 		"",
 		"Run command inside a toolbox container with the given name")
 
+	flags.BoolVar(&runFlags.create,
+		"create",
+		false,
+		"Create the toolbox container if it doesn't exist")
+
 	flags.StringVarP(&runFlags.distro,
 		"distro",
 		"d",
 		"",
 		"Run command inside a toolbox container for a different operating system distribution than the host")
 
+	flags.StringArrayVarP(&runFlags.env,
+		"env",
+		"e",
+		nil,
+		"Set an environment variable inside the toolbox container, as KEY=VALUE or KEY")
+
+	flags.StringArrayVar(&runFlags.envFile,
+		"env-file",
+		nil,
+		"Read environment variables to set inside the toolbox container from a file")
+
 	flags.StringVarP(&runFlags.release,
 		"release",
 		"r",
 		"",
 		"Run command inside a toolbox container for a different operating system release than the host")
 
+	flags.BoolVar(&runFlags.root,
+		"root",
+		false,
+		"Run command as root inside the toolbox container")
+
+	flags.StringVarP(&runFlags.workDir,
+		"workdir",
+		"w",
+		"",
+		"Run command in a different working directory inside the toolbox container")
+
+	markFlagCompletion(flags, "container", completionContainers)
+	markFlagCompletion(flags, "distro", completionDistros)
+	markFlagCompletion(flags, "env-file", completionFiles)
+	markFlagCompletion(flags, "release", completionReleases)
+	markFlagCompletion(flags, "workdir", completionDirectories)
+
 	runCmd.SetHelpFunc(runHelp)
 	rootCmd.AddCommand(runCmd)
 }
@@ -102,6 +170,10 @@ func run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if runFlags.distro != "" {
+		nonDefaultContainer = true
+	}
+
 	var release string
 	if runFlags.release != "" {
 		nonDefaultContainer = true
@@ -125,6 +197,15 @@ func run(cmd *cobra.Command, args []string) error {
 
 	command := args
 
+	options, err := newExecOptions(runFlags.env,
+		runFlags.envFile,
+		runFlags.workDir,
+		runFlags.cleanEnv,
+		runFlags.root)
+	if err != nil {
+		return err
+	}
+
 	container, image, release, err := utils.ResolveContainerAndImageNames(runFlags.container, runFlags.distro, "", release)
 	if err != nil {
 		return err
@@ -135,6 +216,8 @@ func run(cmd *cobra.Command, args []string) error {
 		image,
 		release,
 		command,
+		options,
+		runFlags.create || toolboxConfig.Create.OnDemand,
 		false,
 		false,
 		true); err != nil {
@@ -148,7 +231,8 @@ func runCommand(container string,
 	defaultContainer bool,
 	image, release string,
 	command []string,
-	emitEscapeSequence, fallbackToBash, pedantic bool) error {
+	options execOptions,
+	createMissing, emitEscapeSequence, fallbackToBash, pedantic bool) error {
 	if !pedantic {
 		if image == "" {
 			panic("image not specified")
@@ -159,9 +243,41 @@ func runCommand(container string,
 		}
 	}
 
+	if defaultContainer {
+		var err error
+		// 'run' is meant for scripts, so it ignores the default
+		// chosen interactively with 'enter'
+		container, err = resolveDefaultContainer(container, !pedantic)
+		if err != nil {
+			return err
+		}
+	}
+
 	logrus.Debugf("Checking if container %s exists", container)
 
-	if _, err := podman.ContainerExists(container); err != nil {
+	if _, err := podman.ContainerExists(container); err != nil && createMissing {
+		logrus.Debugf("Container %s not found", container)
+
+		// 'run' mustn't wait for an answer from a script, or read
+		// the input meant for the command
+		if pedantic && !rootFlags.assumeYes && !terminal.IsTerminal(int(os.Stdin.Fd())) {
+			logrus.Debugf("Not offering to create container %s without a terminal", container)
+			err := utils.CreateErrorContainerNotFound(container, executableBase)
+			return err
+		}
+
+		prompt := fmt.Sprintf("Container %s not found. Create now? [y/N]", container)
+
+		created, err := promptToCreateContainer(container, image, release, prompt)
+		if err != nil {
+			return err
+		}
+
+		if !created {
+			err := utils.CreateErrorContainerNotFound(container, executableBase)
+			return err
+		}
+	} else if err != nil {
 		logrus.Debugf("Container %s not found", container)
 
 		if pedantic {
@@ -179,28 +295,18 @@ func runCommand(container string,
 		logrus.Debugf("Found %d containers", containersCount)
 
 		if containersCount == 0 {
-			var shouldCreateContainer bool
-			promptForCreate := true
+			prompt := "No toolbox containers found. Create now? [y/N]"
 
-			if rootFlags.assumeYes {
-				shouldCreateContainer = true
-				promptForCreate = false
-			}
-
-			if promptForCreate {
-				prompt := "No toolbox containers found. Create now? [y/N]"
-				shouldCreateContainer = utils.AskForConfirmation(prompt)
+			created, err := promptToCreateContainer(container, image, release, prompt)
+			if err != nil {
+				return err
 			}
 
-			if !shouldCreateContainer {
-				fmt.Printf("A container can be created later with the 'create' command.\n")
-				fmt.Printf("Run '%s --help' for usage.\n", executableBase)
+			if !created {
+				fmt.Fprintf(os.Stderr, "A container can be created later with the 'create' command.\n")
+				fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", executableBase)
 				return nil
 			}
-
-			if err := createContainer(container, image, release, false); err != nil {
-				return err
-			}
 		} else if containersCount == 1 && defaultContainer {
 			fmt.Fprintf(os.Stderr, "Error: container %s not found\n", container)
 
@@ -208,6 +314,26 @@ func runCommand(container string,
 			fmt.Fprintf(os.Stderr, "Entering container %s instead.\n", container)
 			fmt.Fprintf(os.Stderr, "Use the 'create' command to create a different toolbox.\n")
 			fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", executableBase)
+		} else if defaultContainer && canPickContainer() {
+			fmt.Fprintf(os.Stderr, "Error: container %s not found\n", container)
+
+			pickedContainer, err := pickContainer(containers)
+			if err != nil {
+				return err
+			}
+
+			if pickedContainer == "" {
+				return errors.New("no toolbox container chosen")
+			}
+
+			container = pickedContainer
+
+			prompt := fmt.Sprintf("Use %s by default from now on? [y/N]:", container)
+			if utils.AskForConfirmation(prompt) {
+				if err := saveDefaultContainer(container); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: failed to save the default container: %s\n", err)
+				}
+			}
 		} else {
 			var builder strings.Builder
 			fmt.Fprintf(&builder, "container %s not found\n", container)
@@ -219,6 +345,11 @@ func runCommand(container string,
 		}
 	}
 
+	// Only entering a container is interactive enough to show the hint
+	if !pedantic {
+		showImageUpdateHint(container)
+	}
+
 	if err := callFlatpakSessionHelper(container); err != nil {
 		return err
 	}
@@ -254,13 +385,20 @@ func runCommand(container string,
 	}
 
 	initializedStamp := fmt.Sprintf("%s/container-initialized-%d", toolboxRuntimeDirectory, entryPointPID)
+	statusPath := fmt.Sprintf("%s/container-status-%d.json", toolboxRuntimeDirectory, entryPointPID)
 
 	logrus.Debugf("Checking if initialization stamp %s exists", initializedStamp)
 
 	initializedTimeout := 25 // seconds
 	for i := 0; !utils.PathExists(initializedStamp); i++ {
-		if i == initializedTimeout {
-			return fmt.Errorf("failed to initialize container %s", container)
+		status, err := readInitContainerStatus(statusPath)
+		if err != nil {
+			logrus.Debugf("Reading initialization status of container %s failed: %s", container, err)
+		}
+
+		if i == initializedTimeout || (status != nil && status.State == initContainerStateFailed) {
+			err := createErrorInitializationFailed(container, status)
+			return err
 		}
 
 		time.Sleep(time.Second)
@@ -268,14 +406,19 @@ func runCommand(container string,
 
 	logrus.Debugf("Container %s is initialized", container)
 
-	if err := runCommandWithFallbacks(container, command, emitEscapeSequence, fallbackToBash); err != nil {
+	recordContainerLastUsed(container)
+
+	if err := runCommandWithFallbacks(container, command, options, emitEscapeSequence, fallbackToBash); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func runCommandWithFallbacks(container string, command []string, emitEscapeSequence, fallbackToBash bool) error {
+func runCommandWithFallbacks(container string,
+	command []string,
+	options execOptions,
+	emitEscapeSequence, fallbackToBash bool) error {
 	logrus.Debug("Checking if 'podman exec' supports disabling the detach keys")
 
 	var detachKeysSupported bool
@@ -285,14 +428,44 @@ func runCommandWithFallbacks(container string, command []string, emitEscapeSeque
 		detachKeysSupported = true
 	}
 
-	envOptions := utils.GetEnvOptionsForPreservedVariables()
+	var envOptions []string
+
+	if options.cleanEnv {
+		envOptions = utils.GetEnvOptionsForMinimalVariables()
+	} else {
+		envOptions = utils.GetEnvOptionsForPreservedVariables()
+	}
+
+	if options.root {
+		envOptions = append(envOptions, "--env=USER=root")
+	}
+
+	for _, env := range options.env {
+		envOptions = append(envOptions, "--env="+env)
+	}
+
+	user := currentUser.Username
+	homeDir := currentUser.HomeDir
+
+	if options.root {
+		user = "root"
+		homeDir = "/root"
+	}
 
 	runFallbackCommandsIndex := 0
 	runFallbackWorkDirsIndex := 0
 	workDir := workingDirectory
+	fallbackWorkDirs := runFallbackWorkDirs
+
+	// A working directory that was asked for explicitly isn't silently
+	// replaced with a different one
+	if options.workDir != "" {
+		workDir = options.workDir
+		fallbackWorkDirs = nil
+	}
 
 	for {
-		execArgs := constructExecArgs(container, command, detachKeysSupported, envOptions, workDir)
+		execArgs := constructExecArgs(container, command, detachKeysSupported, envOptions, user, workDir)
 
 		if emitEscapeSequence {
 			fmt.Printf("\033]777;container;push;%s;toolbox;%s\033\\", container, currentUser.Uid)
@@ -323,16 +496,16 @@ func runCommandWithFallbacks(container string, command []string, emitEscapeSeque
 			err = fmt.Errorf("failed to invoke command %s in container %s", command[0], container)
 			return err
 		case 127:
-			if pathPresent, _ := isPathPresent(container, workDir); !pathPresent {
-				if runFallbackWorkDirsIndex < len(runFallbackWorkDirs) {
+			if pathPresent, _ := isPathPresent(container, user, workDir); !pathPresent {
+				if runFallbackWorkDirsIndex < len(fallbackWorkDirs) {
 					fmt.Fprintf(os.Stderr,
 						"Error: directory %s not found in container %s\n",
 						workDir,
 						container)
 
-					workDir = runFallbackWorkDirs[runFallbackWorkDirsIndex]
+					workDir = fallbackWorkDirs[runFallbackWorkDirsIndex]
 					if workDir == "" {
-						workDir = currentUser.HomeDir
+						workDir = homeDir
 					}
 
 					fmt.Fprintf(os.Stderr, "Using %s instead.\n", workDir)
@@ -361,8 +534,88 @@ func runCommandWithFallbacks(container string, command []string, emitEscapeSeque
 			return nil
 		}
 	}
+}
+
+// newExecOptions checks the options of 'enter' and 'run' that affect how a
+// command is run. Variables from env files come first, so that they can be
+// overridden with --env.
+func newExecOptions(envs, envFiles []string, workDir string, cleanEnv, root bool) (execOptions, error) {
+	options := execOptions{cleanEnv: cleanEnv, root: root}
+
+	for _, envFile := range envFiles {
+		fileEnvs, err := utils.ParseEnvFile(envFile)
+		if err != nil {
+			return execOptions{}, err
+		}
+
+		options.env = append(options.env, fileEnvs...)
+	}
+
+	for _, env := range envs {
+		parsedEnv, err := utils.ParseEnv(env)
+		if err != nil {
+			var builder strings.Builder
+			fmt.Fprintf(&builder, "invalid argument for '--env'\n")
+			fmt.Fprintf(&builder, "Environment variables must look like KEY=VALUE or KEY\n")
+			fmt.Fprintf(&builder, "Run '%s --help' for usage.", executableBase)
+
+			errMsg := builder.String()
+			return execOptions{}, errors.New(errMsg)
+		}
+
+		if parsedEnv == "" {
+			logrus.Debugf("Not setting %s, because it's unset", env)
+			continue
+		}
+
+		options.env = append(options.env, parsedEnv)
+	}
+
+	if workDir != "" {
+		if !filepath.IsAbs(workDir) {
+			workDir = filepath.Join(workingDirectory, workDir)
+		}
 
-	panic("code should not be reached")
+		options.workDir = filepath.Clean(workDir)
+	}
+
+	return options, nil
+}
+
+// promptToCreateContainer creates a missing toolbox container, unless the user
+// declines. It returns whether the container was created.
+func promptToCreateContainer(container, image, release, prompt string) (bool, error) {
+	if image == "" || release == "" {
+		panic("image or release not specified")
+	}
+
+	shouldCreateContainer := true
+
+	// The prompt goes to the standard error, so that it doesn't end up
+	// in the output of the command that's run
+	if !rootFlags.assumeYes {
+		shouldCreateContainer = utils.AskForConfirmationOn(os.Stderr, prompt)
+	}
+
+	if !shouldCreateContainer {
+		return false, nil
+	}
+
+	lockfile := config.FindLockfile(workingDirectory)
+
+	created, err := createContainer(container,
+		image,
+		release,
+		lockfile,
+		false,
+		toolboxConfig.Signatures.Verify,
+		false,
+		false)
+	if err != nil {
+		return false, err
+	}
+
+	return created, nil
 }
 
 func runHelp(cmd *cobra.Command, args []string) {
@@ -421,7 +674,7 @@ func constructExecArgs(container string,
 	command []string,
 	detachKeysSupported bool,
 	envOptions []string,
-	workDir string) []string {
+	user, workDir string) []string {
 	var detachKeys []string
 
 	if detachKeysSupported {
@@ -440,22 +693,48 @@ func constructExecArgs(container string,
 	execArgs = append(execArgs, []string{
 		"--interactive",
 		"--tty",
-		"--user", currentUser.Username,
+		"--user", user,
 		"--workdir", workDir,
 	}...)
 
 	execArgs = append(execArgs, envOptions...)
+	execArgs = append(execArgs, container)
 
-	execArgs = append(execArgs, []string{
-		container,
-		"capsh", "--caps=", "--", "-c", "exec \"$@\"", "/bin/sh",
-	}...)
+	// Capabilities are only dropped for the current user, because root
+	// needs them to administer the container
+	if user != "root" {
+		execArgs = append(execArgs, []string{
+			"capsh", "--caps=", "--", "-c", "exec \"$@\"", "/bin/sh",
+		}...)
+	}
 
 	execArgs = append(execArgs, command...)
 
 	return execArgs
 }
 
+// createErrorInitializationFailed quotes the phase of the initialization that
+// failed or didn't finish, if it's known.
+func createErrorInitializationFailed(container string, status *initContainerStatus) error {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "failed to initialize container %s\n", container)
+
+	if status != nil {
+		if phase := status.currentPhase(); phase != nil {
+			if phase.Result == initContainerPhaseFailed {
+				fmt.Fprintf(&builder, "%s failed: %s\n", phase.Name, phase.Error)
+			} else {
+				fmt.Fprintf(&builder, "%s didn't finish\n", phase.Name)
+			}
+		}
+	}
+
+	fmt.Fprintf(&builder, "Run '%s logs %s' for more information.", executableBase, container)
+
+	errMsg := builder.String()
+	return errors.New(errMsg)
+}
+
 func getEntryPointAndPID(container string) (string, int, error) {
 	logrus.Debugf("Inspecting entry point of container %s", container)
 
@@ -488,14 +767,14 @@ func getEntryPointAndPID(container string) (string, int, error) {
 	return entryPoint, entryPointPIDInt, nil
 }
 
-func isPathPresent(container, path string) (bool, error) {
+func isPathPresent(container, user, path string) (bool, error) {
 	logrus.Debugf("Looking for path %s in container %s", path, container)
 
 	logLevelString := podman.LogLevel.String()
 	args := []string{
 		"--log-level", logLevelString,
 		"exec",
-		"--user", currentUser.Username,
+		"--user", user,
 		container,
 		"sh", "-c", "test -d \"$1\"", "sh", path,
 	}
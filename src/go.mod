@@ -3,15 +3,18 @@ module github.com/containers/toolbox
 go 1.13
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/HarryMichal/go-version v1.0.0
 	github.com/acobaugh/osrelease v0.0.0-20181218015638-a93a0a55a249
 	github.com/briandowns/spinner v1.10.0
+	github.com/creack/pty v1.1.11
 	github.com/docker/go-units v0.4.0
 	github.com/fsnotify/fsnotify v1.4.7
 	github.com/godbus/dbus/v5 v5.0.3
 	github.com/mattn/go-isatty v0.0.8
 	github.com/sirupsen/logrus v1.4.2
 	github.com/spf13/cobra v0.0.5
+	github.com/spf13/pflag v1.0.3
 	github.com/stretchr/testify v1.7.0
 	golang.org/x/crypto v0.0.0-20181203042331-505ab145d0a9
 	golang.org/x/sys v0.0.0-20190422165155-953cdadca894
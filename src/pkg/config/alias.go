@@ -0,0 +1,158 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ExpandAlias expands the command line in definition with args. In each word
+// of definition, $1, $2 and so on are replaced by the corresponding
+// argument, $@ as a whole word is replaced by all the arguments, and $$ is
+// replaced by a $. If definition doesn't refer to the arguments, they are
+// appended to it.
+func ExpandAlias(definition string, args []string) ([]string, error) {
+	words, err := SplitCommandLine(definition)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(words) == 0 {
+		return nil, fmt.Errorf("empty command line")
+	}
+
+	var expanded []string
+	usesArgs := false
+
+	for _, word := range words {
+		if word == "$@" {
+			expanded = append(expanded, args...)
+			usesArgs = true
+			continue
+		}
+
+		var builder strings.Builder
+
+		for i := 0; i < len(word); i++ {
+			if word[i] != '$' || i == len(word)-1 {
+				builder.WriteByte(word[i])
+				continue
+			}
+
+			if word[i+1] == '$' {
+				builder.WriteByte('$')
+				i++
+				continue
+			}
+
+			end := i + 1
+			for end < len(word) && word[end] >= '0' && word[end] <= '9' {
+				end++
+			}
+
+			if end == i+1 {
+				builder.WriteByte(word[i])
+				continue
+			}
+
+			index, err := strconv.Atoi(word[i+1 : end])
+			if err != nil || index == 0 {
+				return nil, fmt.Errorf("invalid argument %s", word[i:end])
+			}
+
+			if index > len(args) {
+				return nil, fmt.Errorf("missing argument %d", index)
+			}
+
+			builder.WriteString(args[index-1])
+			usesArgs = true
+			i = end - 1
+		}
+
+		expanded = append(expanded, builder.String())
+	}
+
+	if !usesArgs {
+		expanded = append(expanded, args...)
+	}
+
+	return expanded, nil
+}
+
+// SplitCommandLine splits commandLine into words separated by white space,
+// like a shell would. Single and double quotes group words with white space,
+// and a backslash outside single quotes escapes the next character.
+func SplitCommandLine(commandLine string) ([]string, error) {
+	var words []string
+	var builder strings.Builder
+
+	inWord := false
+	var quote rune
+
+	runes := []rune(commandLine)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				builder.WriteRune(r)
+			}
+		case r == '\\':
+			if i == len(runes)-1 {
+				return nil, fmt.Errorf("unfinished escape sequence")
+			}
+
+			i++
+			builder.WriteRune(runes[i])
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				builder.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, builder.String())
+				builder.Reset()
+				inWord = false
+			}
+		default:
+			builder.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote %c", quote)
+	}
+
+	if inWord {
+		words = append(words, builder.String())
+	}
+
+	return words, nil
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/containers/toolbox/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandAlias(t *testing.T) {
+	testCases := []struct {
+		name       string
+		definition string
+		args       []string
+		expanded   []string
+		err        string
+	}{
+		{
+			name:       "Arguments are appended",
+			definition: "run -c dev-f35 make -j8",
+			args:       []string{"install"},
+			expanded:   []string{"run", "-c", "dev-f35", "make", "-j8", "install"},
+		},
+		{
+			name:       "Numbered arguments",
+			definition: "run -c $1 make -j8",
+			args:       []string{"dev-f35", "install"},
+			expanded:   []string{"run", "-c", "dev-f35", "make", "-j8"},
+		},
+		{
+			name:       "Numbered arguments inside words",
+			definition: "enter dev-f$1",
+			args:       []string{"35"},
+			expanded:   []string{"enter", "dev-f35"},
+		},
+		{
+			name:       "All arguments",
+			definition: "run -c $1 make $@",
+			args:       []string{"dev-f35", "-j8"},
+			expanded:   []string{"run", "-c", "dev-f35", "make", "dev-f35", "-j8"},
+		},
+		{
+			name:       "Literal dollar signs",
+			definition: "run echo $$HOME $",
+			args:       nil,
+			expanded:   []string{"run", "echo", "$HOME", "$"},
+		},
+		{
+			name:       "Missing argument",
+			definition: "run -c $2",
+			args:       []string{"dev-f35"},
+			err:        "missing argument 2",
+		},
+		{
+			name:       "Argument zero",
+			definition: "run $0",
+			args:       []string{"foo"},
+			err:        "invalid argument $0",
+		},
+		{
+			name:       "Empty definition",
+			definition: " ",
+			err:        "empty command line",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expanded, err := config.ExpandAlias(tc.definition, tc.args)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expanded, expanded)
+		})
+	}
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	testCases := []struct {
+		name        string
+		commandLine string
+		words       []string
+		err         string
+	}{
+		{
+			name:        "White space",
+			commandLine: "  run\t-c  dev  ",
+			words:       []string{"run", "-c", "dev"},
+		},
+		{
+			name:        "Quotes",
+			commandLine: `run sh -c 'echo "$HOME"' "a b" ""`,
+			words:       []string{"run", "sh", "-c", `echo "$HOME"`, "a b", ""},
+		},
+		{
+			name:        "Escapes",
+			commandLine: `run echo a\ b \"c\" "d\"e"`,
+			words:       []string{"run", "echo", "a b", `"c"`, `d"e`},
+		},
+		{
+			name:        "Unterminated quote",
+			commandLine: `run 'echo`,
+			err:         "unterminated quote '",
+		},
+		{
+			name:        "Unfinished escape sequence",
+			commandLine: `run echo \`,
+			err:         "unfinished escape sequence",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			words, err := config.SplitCommandLine(tc.commandLine)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.words, words)
+		})
+	}
+}
@@ -0,0 +1,217 @@
+/*
+ * Copyright © 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/docker/go-units"
+	"github.com/sirupsen/logrus"
+)
+
+// Create holds the options that affect how toolbox containers are created.
+type Create struct {
+	// OnDemand creates missing toolbox containers on 'enter' and 'run',
+	// like their --create option.
+	OnDemand bool `toml:"on_demand"`
+
+	// SkipGroups lists the host's supplementary groups that shouldn't be
+	// mirrored inside toolbox containers.
+	SkipGroups []string `toml:"skip_groups"`
+}
+
+// Duration is a time.Duration that can be written as a string like "12h" or
+// "30m" in the configuration files.
+type Duration struct {
+	time.Duration
+}
+
+// Environment holds the options that affect which environment variables are
+// forwarded from the host to toolbox containers.
+type Environment struct {
+	// Allow lists glob patterns, like "XDG_*" or "*_PROXY", for variables
+	// that are forwarded in addition to the built-in ones.
+	Allow []string `toml:"allow"`
+
+	// Deny lists glob patterns for variables that are never forwarded. It
+	// takes precedence over Allow and the built-in variables.
+	Deny []string `toml:"deny"`
+}
+
+// Image holds the options that affect how toolbox images are checked for
+// updates.
+type Image struct {
+	// CheckOnEnter enables a hint on 'enter' when a newer version of the
+	// container's image is available in its registry.
+	CheckOnEnter bool `toml:"check_on_enter"`
+
+	// CheckInterval is the shortest time between two such checks.
+	CheckInterval Duration `toml:"check_interval"`
+}
+
+// Log holds the options that affect how and where log messages are written.
+type Log struct {
+	// File writes log messages to $XDG_STATE_HOME/toolbox/toolbox.log
+	// instead of the standard error, like the --log-file option.
+	File bool `toml:"file"`
+
+	// Format is either "text" or "json", like the --log-format option.
+	Format string `toml:"format"`
+
+	// Level is the level of the messages that are logged, like the
+	// --log-level option.
+	Level string `toml:"level"`
+
+	// MaxFiles is the number of rotated log files that are kept.
+	MaxFiles *int `toml:"max_files"`
+
+	// MaxSize is the size above which the log file is rotated.
+	MaxSize Size `toml:"max_size"`
+}
+
+// MaintenanceTask holds the options for a periodic task run by the entry point
+// of toolbox containers.
+type MaintenanceTask struct {
+	// Command is required for tasks other than the built-in ones.
+	Command []string `toml:"command"`
+
+	// Enabled is nil if it's not set in any configuration file.
+	Enabled *bool `toml:"enabled"`
+
+	Interval Duration `toml:"interval"`
+
+	// Jitter is the upper bound of a random delay added to each interval.
+	Jitter Duration `toml:"jitter"`
+}
+
+// Signatures holds the options for verifying the signatures of images before
+// toolbox containers are created from them.
+type Signatures struct {
+	// Verify requires images to be signed.
+	Verify bool `toml:"verify"`
+
+	// Policy is a containers-policy.json(5) file to verify images with,
+	// instead of the one used by Podman.
+	Policy string `toml:"policy"`
+
+	// Key is a public key to verify sigstore signatures with cosign(1). It
+	// takes precedence over Policy.
+	Key string `toml:"key"`
+}
+
+// Config is the merged configuration from all the configuration files.
+type Config struct {
+	// Aliases maps the names of aliases to the command lines that they
+	// expand to.
+	Aliases map[string]string `toml:"aliases"`
+
+	Create Create `toml:"create"`
+
+	// Directories maps paths to the toolbox containers used in them and
+	// their subdirectories.
+	Directories map[string]string `toml:"directories"`
+
+	Environment Environment `toml:"environment"`
+
+	Image Image `toml:"image"`
+
+	Log Log `toml:"log"`
+
+	// Maintenance maps the names of periodic tasks to their options.
+	Maintenance map[string]MaintenanceTask `toml:"maintenance"`
+
+	Signatures Signatures `toml:"signatures"`
+}
+
+// Size is a number of bytes that can be written as a string like "10MB" or
+// "512KiB" in the configuration files.
+type Size struct {
+	Bytes int64
+}
+
+const (
+	systemConfigFile = "/etc/containers/toolbox.conf"
+)
+
+// Load reads the system-wide configuration file followed by the one in the
+// user's configuration directory, so that the latter can override the former.
+// Missing files are not an error.
+func Load() (*Config, error) {
+	paths := []string{systemConfigFile}
+
+	if configDir, err := os.UserConfigDir(); err == nil {
+		userConfigFile := filepath.Join(configDir, "containers", "toolbox.conf")
+		paths = append(paths, userConfigFile)
+	} else {
+		logrus.Debugf("Loading configuration: failed to get the user config directory: %s", err)
+	}
+
+	return LoadFiles(paths...)
+}
+
+// LoadFiles reads the given configuration files in order. Settings in later
+// files override those in earlier ones.
+func LoadFiles(paths ...string) (*Config, error) {
+	var config Config
+
+	for _, path := range paths {
+		logrus.Debugf("Loading configuration file %s", path)
+
+		if _, err := toml.DecodeFile(path, &config); err != nil {
+			if os.IsNotExist(err) {
+				logrus.Debugf("Configuration file %s not found", path)
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to parse configuration file %s: %w", path, err)
+		}
+	}
+
+	return &config, nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	if duration < 0 {
+		return fmt.Errorf("duration %s must not be negative", text)
+	}
+
+	d.Duration = duration
+	return nil
+}
+
+func (s *Size) UnmarshalText(text []byte) error {
+	size, err := units.RAMInBytes(string(text))
+	if err != nil {
+		return err
+	}
+
+	if size < 0 {
+		return fmt.Errorf("size %s must not be negative", text)
+	}
+
+	s.Bytes = size
+	return nil
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// MarkerName is the name of the file that selects the toolbox container
+	// for a directory and its subdirectories
+	MarkerName = ".toolbox"
+)
+
+// ContainerForDirectory returns the toolbox container mapped to dir, or to the
+// closest of its parents, in the [directories] table, along with the mapped
+// path. Paths in the table can start with ~/ for homeDir. An empty string is
+// returned if there's no mapping.
+func (config *Config) ContainerForDirectory(dir, homeDir string) (string, string) {
+	type mapping struct {
+		expandedPath string
+		path         string
+	}
+
+	var mappings []mapping
+
+	for path := range config.Directories {
+		expandedPath := path
+		if path == "~" || strings.HasPrefix(path, "~/") {
+			expandedPath = homeDir + path[1:]
+		}
+
+		expandedPath = filepath.Clean(expandedPath)
+		mappings = append(mappings, mapping{expandedPath, path})
+	}
+
+	// Longer paths are more specific, and the order of paths that expand
+	// to the same one mustn't depend on the map
+	sort.Slice(mappings, func(i, j int) bool {
+		if len(mappings[i].expandedPath) != len(mappings[j].expandedPath) {
+			return len(mappings[i].expandedPath) > len(mappings[j].expandedPath)
+		}
+
+		return mappings[i].path < mappings[j].path
+	})
+
+	dir = filepath.Clean(dir)
+
+	for _, mapping := range mappings {
+		expandedPath := mapping.expandedPath
+
+		if dir == expandedPath || strings.HasPrefix(dir, expandedPath+"/") || expandedPath == "/" {
+			return config.Directories[mapping.path], expandedPath
+		}
+	}
+
+	return "", ""
+}
+
+// FindMarker looks for a marker file in dir and its parents, and returns the
+// name of the toolbox container in it, along with the marker's location. The
+// container is the first line that's not empty or a comment. An empty string
+// is returned if there's no marker.
+func FindMarker(dir string) (string, string, error) {
+	path := findInParents(dir, MarkerName)
+	if path == "" {
+		return "", "", nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		return line, path, nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return "", "", fmt.Errorf("%s doesn't name a container", path)
+}
+
+// findInParents looks for a regular file called name in dir and its parents.
+// Directories and other kinds of files with that name are skipped. An empty
+// string is returned if there's none.
+func findInParents(dir, name string) string {
+	for {
+		path := filepath.Join(dir, name)
+		if fileInfo, err := os.Stat(path); err == nil && fileInfo.Mode().IsRegular() {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+
+		dir = parent
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containers/toolbox/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerForDirectory(t *testing.T) {
+	toolboxConfig := config.Config{
+		Directories: map[string]string{
+			"~/src":          "devel",
+			"~/src/gnome":    "gnome",
+			"/srv/work/":     "work",
+			"~/src/gnome-os": "gnome-os",
+			"/home/user/doc": "doc",
+			"~/doc/notes":    "notes",
+		},
+	}
+
+	testCases := []struct {
+		dir       string
+		container string
+		path      string
+	}{
+		{"/home/user/src", "devel", "/home/user/src"},
+		{"/home/user/src/toolbox", "devel", "/home/user/src"},
+		{"/home/user/src/gnome/gnome-shell", "gnome", "/home/user/src/gnome"},
+		{"/home/user/src/gnome-os", "gnome-os", "/home/user/src/gnome-os"},
+		{"/home/user/src/gnome-builder", "devel", "/home/user/src"},
+		{"/srv/work", "work", "/srv/work"},
+		{"/srv/workshop", "", ""},
+		{"/home/user", "", ""},
+		{"/home/user/doc/letters", "doc", "/home/user/doc"},
+		{"/home/user/doc/notes/2021", "notes", "/home/user/doc/notes"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.dir, func(t *testing.T) {
+			container, path := toolboxConfig.ContainerForDirectory(tc.dir, "/home/user")
+			assert.Equal(t, tc.container, container)
+			assert.Equal(t, tc.path, path)
+		})
+	}
+}
+
+func TestFindMarker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "toolbox-config-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	subdir := filepath.Join(dir, "src", "foo")
+	require.NoError(t, os.MkdirAll(subdir, 0755))
+
+	container, path, err := config.FindMarker(subdir)
+	require.NoError(t, err)
+	assert.Equal(t, "", container)
+	assert.Equal(t, "", path)
+
+	markerPath := filepath.Join(dir, "src", config.MarkerName)
+	require.NoError(t, ioutil.WriteFile(markerPath, []byte("# Used for foo\n\n  foo-devel  \n"), 0644))
+
+	container, path, err = config.FindMarker(subdir)
+	require.NoError(t, err)
+	assert.Equal(t, "foo-devel", container)
+	assert.Equal(t, markerPath, path)
+
+	require.NoError(t, ioutil.WriteFile(markerPath, []byte("# Nothing here\n"), 0644))
+
+	_, _, err = config.FindMarker(subdir)
+	assert.Error(t, err)
+
+	// Directories with the same name as the marker are skipped
+	require.NoError(t, os.Remove(markerPath))
+	require.NoError(t, os.Mkdir(markerPath, 0755))
+
+	container, path, err = config.FindMarker(subdir)
+	require.NoError(t, err)
+	assert.Equal(t, "", container)
+	assert.Equal(t, "", path)
+}
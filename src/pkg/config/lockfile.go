@@ -0,0 +1,108 @@
+/*
+ * Copyright © 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// LockfileName is the name of the lockfile that's looked for in a
+	// project's directory
+	LockfileName = "toolbox.lock"
+
+	lockfileHeader = "# Written by 'toolbox create --pin'. Commit this file to share it.\n\n"
+)
+
+// LockedImage holds the digest that an image reference was resolved to.
+type LockedImage struct {
+	Digest string `toml:"digest"`
+}
+
+// Lockfile maps image references with tags, like
+// registry.fedoraproject.org/fedora-toolbox:35, to digests, so that everyone
+// working on a project creates toolbox containers from the same image.
+type Lockfile struct {
+	Images map[string]LockedImage `toml:"images"`
+
+	path string
+}
+
+// FindLockfile looks for a lockfile in dir and its parents. An empty string
+// is returned if there's none.
+func FindLockfile(dir string) string {
+	return findInParents(dir, LockfileName)
+}
+
+// LoadLockfile reads the lockfile at path. A missing file is not an error,
+// and results in an empty lockfile that will be written to path when saved.
+func LoadLockfile(path string) (*Lockfile, error) {
+	lockfile := &Lockfile{
+		Images: make(map[string]LockedImage),
+		path:   path,
+	}
+
+	logrus.Debugf("Loading lockfile %s", path)
+
+	if _, err := toml.DecodeFile(path, lockfile); err != nil {
+		if os.IsNotExist(err) {
+			logrus.Debugf("Lockfile %s not found", path)
+			return lockfile, nil
+		}
+
+		return nil, fmt.Errorf("failed to parse lockfile %s: %w", path, err)
+	}
+
+	return lockfile, nil
+}
+
+// Digest returns the digest that image is locked to, or an empty string.
+func (lockfile *Lockfile) Digest(image string) string {
+	return lockfile.Images[image].Digest
+}
+
+// Path returns the location of the lockfile.
+func (lockfile *Lockfile) Path() string {
+	return lockfile.path
+}
+
+// Save writes the lockfile back to its location.
+func (lockfile *Lockfile) Save() error {
+	var buffer bytes.Buffer
+	buffer.WriteString(lockfileHeader)
+
+	if err := toml.NewEncoder(&buffer).Encode(lockfile); err != nil {
+		return fmt.Errorf("failed to encode lockfile %s: %w", lockfile.path, err)
+	}
+
+	if err := ioutil.WriteFile(lockfile.path, buffer.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write lockfile %s: %w", lockfile.path, err)
+	}
+
+	return nil
+}
+
+// SetDigest locks image to digest.
+func (lockfile *Lockfile) SetDigest(image, digest string) {
+	lockfile.Images[image] = LockedImage{Digest: digest}
+}
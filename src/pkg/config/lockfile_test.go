@@ -0,0 +1,43 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containers/toolbox/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "toolbox-config-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	subdir := filepath.Join(dir, "src", "foo")
+	require.NoError(t, os.MkdirAll(subdir, 0755))
+
+	assert.Equal(t, "", config.FindLockfile(subdir))
+
+	path := filepath.Join(dir, config.LockfileName)
+
+	lockfile, err := config.LoadLockfile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "", lockfile.Digest("registry.fedoraproject.org/fedora-toolbox:35"))
+
+	lockfile.SetDigest("registry.fedoraproject.org/fedora-toolbox:35", "sha256:1234")
+	require.NoError(t, lockfile.Save())
+
+	assert.Equal(t, path, config.FindLockfile(subdir))
+
+	lockfile, err = config.LoadLockfile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:1234", lockfile.Digest("registry.fedoraproject.org/fedora-toolbox:35"))
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("[images\n"), 0644))
+
+	_, err = config.LoadLockfile(path)
+	assert.Error(t, err)
+}
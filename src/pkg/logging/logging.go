@@ -0,0 +1,85 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package logging writes log messages to files that are rotated when they
+// grow too big.
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// OpenFile opens the log file at path for appending, creating it and its
+// parent directories if needed. If the file is larger than maxSize, it's
+// rotated first: path is renamed to path.1, path.1 to path.2 and so on, and
+// only maxFiles of the rotated files are kept. A maxSize of 0 disables
+// rotation.
+func OpenFile(path string, maxSize int64, maxFiles int) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create the directory for log file %s: %w", path, err)
+	}
+
+	if maxSize > 0 {
+		if fileInfo, err := os.Stat(path); err == nil && fileInfo.Size() > maxSize {
+			if err := rotate(path, maxFiles); err != nil {
+				return nil, fmt.Errorf("failed to rotate log file %s: %w", path, err)
+			}
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file %s: %w", path, err)
+	}
+
+	return file, nil
+}
+
+func getRotatedPath(path string, index int) string {
+	return fmt.Sprintf("%s.%d", path, index)
+}
+
+// rotate rotates the log file at path. Other toolbox processes might be
+// rotating it at the same time, so files that have already been moved away
+// aren't an error.
+func rotate(path string, maxFiles int) error {
+	if maxFiles <= 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		return nil
+	}
+
+	if err := os.Remove(getRotatedPath(path, maxFiles)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for index := maxFiles - 1; index > 0; index-- {
+		err := os.Rename(getRotatedPath(path, index), getRotatedPath(path, index+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if err := os.Rename(path, getRotatedPath(path, 1)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logging_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/containers/toolbox/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeLog(t *testing.T, path, content string, maxSize int64, maxFiles int) {
+	file, err := logging.OpenFile(path, maxSize, maxFiles)
+	require.NoError(t, err)
+	defer file.Close()
+
+	_, err = file.WriteString(content)
+	require.NoError(t, err)
+}
+
+func readLog(t *testing.T, path string) string {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ""
+	}
+
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestOpenFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "toolbox-test-logging")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "state", "toolbox", "toolbox.log")
+
+	writeLog(t, path, "a\n", 10, 2)
+	writeLog(t, path, "b\n", 10, 2)
+	assert.Equal(t, "a\nb\n", readLog(t, path))
+
+	fileInfo, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fileInfo.Mode().Perm())
+
+	// Rotated once it's larger than the maximum size
+	writeLog(t, path, strings.Repeat("c", 10)+"\n", 10, 2)
+	writeLog(t, path, "d\n", 10, 2)
+	assert.Equal(t, "d\n", readLog(t, path))
+	assert.Equal(t, "a\nb\n"+strings.Repeat("c", 10)+"\n", readLog(t, path+".1"))
+
+	writeLog(t, path, strings.Repeat("e", 10)+"\n", 10, 2)
+	writeLog(t, path, "f\n", 10, 2)
+	assert.Equal(t, "f\n", readLog(t, path))
+	assert.Equal(t, "d\n"+strings.Repeat("e", 10)+"\n", readLog(t, path+".1"))
+	assert.Equal(t, "a\nb\n"+strings.Repeat("c", 10)+"\n", readLog(t, path+".2"))
+
+	// Only the given number of rotated files are kept
+	writeLog(t, path, strings.Repeat("g", 10)+"\n", 10, 2)
+	writeLog(t, path, "h\n", 10, 2)
+	assert.Equal(t, "h\n", readLog(t, path))
+	assert.Equal(t, "f\n"+strings.Repeat("g", 10)+"\n", readLog(t, path+".1"))
+	assert.Equal(t, "d\n"+strings.Repeat("e", 10)+"\n", readLog(t, path+".2"))
+	assert.Equal(t, "", readLog(t, path+".3"))
+}
+
+func TestOpenFileWithoutRotatedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "toolbox-test-logging")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "toolbox.log")
+
+	writeLog(t, path, strings.Repeat("a", 10)+"\n", 10, 0)
+	writeLog(t, path, "b\n", 10, 0)
+	assert.Equal(t, "b\n", readLog(t, path))
+	assert.Equal(t, "", readLog(t, path+".1"))
+}
+
+func TestOpenFileWithoutRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "toolbox-test-logging")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "toolbox.log")
+
+	writeLog(t, path, strings.Repeat("a", 10)+"\n", 0, 2)
+	writeLog(t, path, "b\n", 0, 2)
+	assert.Equal(t, strings.Repeat("a", 10)+"\nb\n", readLog(t, path))
+}
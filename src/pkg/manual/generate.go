@@ -0,0 +1,102 @@
+//go:build ignore
+// +build ignore
+
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// This program writes manuals.go with the Markdown sources of the manuals in
+// the doc directory. Run it with 'go generate' after changing them.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const header = `/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Code generated by generate.go; DO NOT EDIT.
+
+package manual
+
+`
+
+func main() {
+	if err := generate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func generate() error {
+	paths, err := filepath.Glob("../../../doc/*.md")
+	if err != nil {
+		return err
+	}
+
+	if len(paths) == 0 {
+		return fmt.Errorf("no manuals found")
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(header)
+	buffer.WriteString("var manuals = map[string]string{\n")
+
+	for _, path := range paths {
+		source, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		// 'toolbox-enter.1.md' is called 'toolbox-enter'
+		name := strings.TrimSuffix(filepath.Base(path), ".md")
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+
+		fmt.Fprintf(&buffer, "\t%q: %q,\n", name, source)
+	}
+
+	buffer.WriteString("}\n")
+
+	formatted, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile("manuals.go", formatted, 0644); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package manual holds the manuals from the doc directory, so that they can be
+// shown when man(1) or the installed manuals are unavailable, as is often the
+// case inside minimal container images.
+package manual
+
+//go:generate go run generate.go
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	bodyIndent       = "       "
+	codeIndent       = "           "
+	subsectionIndent = "   "
+)
+
+var (
+	boldRegexp      = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	codeRegexp      = regexp.MustCompile("`([^`]+)`")
+	escapeRegexp    = regexp.MustCompile(`\\(.)`)
+	underlineRegexp = regexp.MustCompile(`\*([^*]+)\*`)
+)
+
+// Get returns the Markdown source of the manual called name, like
+// 'toolbox-enter' or 'toolbox.conf'.
+func Get(name string) (string, bool) {
+	source, ok := manuals[name]
+	return source, ok
+}
+
+// Render writes the Markdown source of a manual to w, formatted like man(1)
+// would format it. If styled is true, bold and underlined text are marked
+// with terminal escape sequences.
+func Render(w io.Writer, source string, styled bool) error {
+	var bold, reset, underline string
+	if styled {
+		bold = "\033[1m"
+		reset = "\033[0m"
+		underline = "\033[4m"
+	}
+
+	renderInline := func(text string) string {
+		text = escapeRegexp.ReplaceAllString(text, "$1")
+		text = codeRegexp.ReplaceAllString(text, "$1")
+		text = boldRegexp.ReplaceAllString(text, bold+"$1"+reset)
+		text = underlineRegexp.ReplaceAllString(text, underline+"$1"+reset)
+		return text
+	}
+
+	var builder strings.Builder
+	inCodeBlock := false
+
+	for _, line := range strings.Split(source, "\n") {
+		switch {
+		case strings.HasPrefix(line, "```"):
+			inCodeBlock = !inCodeBlock
+		case inCodeBlock:
+			fmt.Fprintf(&builder, "%s%s\n", codeIndent, line)
+		case strings.HasPrefix(line, "% "):
+			title := strings.ToUpper(strings.TrimPrefix(line, "% "))
+			fmt.Fprintf(&builder, "%s\n", title)
+		case strings.HasPrefix(line, "### "):
+			heading := strings.TrimPrefix(line, "### ")
+			fmt.Fprintf(&builder, "%s%s%s%s\n", subsectionIndent, bold, renderInline(heading), reset)
+		case strings.HasPrefix(line, "## "):
+			heading := strings.TrimSpace(strings.TrimRight(strings.TrimPrefix(line, "## "), "#"))
+			fmt.Fprintf(&builder, "%s%s%s\n", bold, strings.ToUpper(heading), reset)
+		case line == "":
+			fmt.Fprintf(&builder, "\n")
+		default:
+			fmt.Fprintf(&builder, "%s%s\n", bodyIndent, renderInline(line))
+		}
+	}
+
+	output := strings.TrimRight(builder.String(), "\n") + "\n"
+	if _, err := io.WriteString(w, output); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manual_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/containers/toolbox/pkg/manual"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManualsAreUpToDate(t *testing.T) {
+	paths, err := filepath.Glob("../../../doc/*.md")
+	require.NoError(t, err)
+
+	if len(paths) == 0 {
+		t.Skip("the doc directory isn't available")
+	}
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".md")
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+
+		t.Run(name, func(t *testing.T) {
+			expected, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+
+			source, ok := manual.Get(name)
+			require.True(t, ok, "manual %s is missing: run 'go generate ./pkg/manual'", name)
+			assert.Equal(t, string(expected), source, "manual %s is outdated: run 'go generate ./pkg/manual'", name)
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	source, ok := manual.Get("toolbox-enter")
+	assert.True(t, ok)
+	assert.True(t, strings.HasPrefix(source, "% toolbox-enter(1)\n"))
+
+	_, ok = manual.Get("toolbox-foo")
+	assert.False(t, ok)
+}
+
+func TestRender(t *testing.T) {
+	source := "% toolbox-foo(1)\n" +
+		"\n" +
+		"## NAME\n" +
+		"toolbox\\-foo - Do *FOO* with **--bar**\n" +
+		"\n" +
+		"## OPTIONS ##\n" +
+		"\n" +
+		"### Use `baz`\n" +
+		"\n" +
+		"```\n" +
+		"$ toolbox foo\n" +
+		"```\n"
+
+	testCases := []struct {
+		name     string
+		styled   bool
+		expected string
+	}{
+		{
+			name:   "Plain",
+			styled: false,
+			expected: "TOOLBOX-FOO(1)\n" +
+				"\n" +
+				"NAME\n" +
+				"       toolbox-foo - Do FOO with --bar\n" +
+				"\n" +
+				"OPTIONS\n" +
+				"\n" +
+				"   Use baz\n" +
+				"\n" +
+				"           $ toolbox foo\n",
+		},
+		{
+			name:   "Styled",
+			styled: true,
+			expected: "TOOLBOX-FOO(1)\n" +
+				"\n" +
+				"\033[1mNAME\033[0m\n" +
+				"       toolbox-foo - Do \033[4mFOO\033[0m with \033[1m--bar\033[0m\n" +
+				"\n" +
+				"\033[1mOPTIONS\033[0m\n" +
+				"\n" +
+				"   \033[1mUse baz\033[0m\n" +
+				"\n" +
+				"           $ toolbox foo\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+
+			err := manual.Render(&buffer, source, tc.styled)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, buffer.String())
+		})
+	}
+}
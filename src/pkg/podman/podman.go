@@ -21,9 +21,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 
 	"github.com/HarryMichal/go-version"
 	"github.com/containers/toolbox/pkg/shell"
+	"github.com/creack/pty"
 	"github.com/sirupsen/logrus"
 )
 
@@ -35,6 +38,38 @@ var (
 	LogLevel = logrus.ErrorLevel
 )
 
+// Build is a wrapper around the 'podman build' command
+//
+// Parameter containerfile can be empty to let Podman look for a Containerfile
+// or Dockerfile in contextDir. The labels are added to the built image.
+func Build(contextDir, containerfile, tag string, labels map[string]string, stdout, stderr io.Writer) error {
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "build"}
+
+	if containerfile != "" {
+		args = append(args, "--file", containerfile)
+	}
+
+	var labelNames []string
+	for label := range labels {
+		labelNames = append(labelNames, label)
+	}
+
+	sort.Strings(labelNames)
+
+	for _, label := range labelNames {
+		args = append(args, "--label", label+"="+labels[label])
+	}
+
+	args = append(args, "--tag", tag, contextDir)
+
+	if err := shell.Run("podman", nil, stdout, stderr, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // CheckVersion compares provided version with the version of Podman.
 //
 // Takes in one string parameter that should be in the format that is used for versioning (eg. 1.0.0, 2.5.1-dev).
@@ -121,6 +156,22 @@ func GetImages(args ...string) ([]map[string]interface{}, error) {
 	return images, nil
 }
 
+// GetOCIRuntime returns the name of the OCI runtime used by Podman, like crun
+// or runc.
+func GetOCIRuntime() (string, error) {
+	var stdout bytes.Buffer
+
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "info", "--format", "{{.Host.OCIRuntime.Name}}"}
+
+	if err := shell.Run("podman", nil, &stdout, nil, args...); err != nil {
+		return "", err
+	}
+
+	ociRuntime := strings.TrimSpace(stdout.String())
+	return ociRuntime, nil
+}
+
 // GetVersion returns version of Podman in a string
 func GetVersion() (string, error) {
 	if podmanVersion != "" {
@@ -226,18 +277,144 @@ func IsToolboxImage(image string) (bool, error) {
 	return true, nil
 }
 
-// Pull pulls an image
-func Pull(imageName string) error {
+// Load is a wrapper around the 'podman load' command
+//
+// Parameter archive is the path to an image archive in the docker-archive or
+// oci-archive format. The names of the loaded images are returned. Images
+// without a name are represented by their IDs.
+func Load(archive string) ([]string, error) {
+	var stdout bytes.Buffer
+
 	logLevelString := LogLevel.String()
-	args := []string{"--log-level", logLevelString, "pull", imageName}
+	args := []string{"--log-level", logLevelString, "load", "--input", archive}
 
-	if err := shell.Run("podman", nil, nil, nil, args...); err != nil {
+	if err := shell.Run("podman", nil, &stdout, nil, args...); err != nil {
+		return nil, err
+	}
+
+	// The output is like 'Loaded image(s): localhost/foo:latest,...' or,
+	// with newer Podman versions, one 'Loaded image: ...' line per image
+	var images []string
+
+	output := stdout.String()
+	for _, line := range strings.Split(output, "\n") {
+		i := strings.Index(line, ":")
+		if i == -1 || !strings.HasPrefix(line, "Loaded image") {
+			continue
+		}
+
+		for _, image := range strings.Split(line[i+1:], ",") {
+			image = strings.TrimSpace(image)
+			if image != "" {
+				images = append(images, image)
+			}
+		}
+	}
+
+	if len(images) == 0 {
+		return nil, fmt.Errorf("failed to find the images loaded from %s", archive)
+	}
+
+	return images, nil
+}
+
+// Logs is a wrapper around 'podman logs' command
+//
+// If follow is true, the output of the container is streamed until it stops.
+func Logs(container string, follow bool, stdout, stderr io.Writer) error {
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "logs"}
+
+	if follow {
+		args = append(args, "--follow")
+	}
+
+	args = append(args, container)
+
+	if err := shell.Run("podman", nil, stdout, stderr, args...); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// Pull pulls an image
+//
+// If progress is not nil, it's called as the pull makes progress. Podman only
+// reports the progress of each blob in bytes on a terminal, so it's run on a
+// pseudo-terminal if one can be opened.
+//
+// If signaturePolicy is set, the image is verified against that policy file
+// instead of the default one. When pulling fails, the error carries Podman's
+// explanation, like the reason a signature policy rejected the image.
+func Pull(imageName, signaturePolicy string, progress func(PullProgress)) error {
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "pull"}
+
+	if signaturePolicy != "" {
+		args = append(args, "--signature-policy", signaturePolicy)
+	}
+
+	args = append(args, imageName)
+
+	parser := newPullProgressParser(progress)
+
+	if progress == nil {
+		var stderr io.Writer = parser
+		if logLevel := logrus.GetLevel(); logLevel >= logrus.DebugLevel {
+			stderrWriter := shell.NewLogWriter(logrus.DebugLevel)
+			defer stderrWriter.Flush()
+
+			stderr = io.MultiWriter(stderrWriter, parser)
+		}
+
+		if err := shell.Run("podman", nil, nil, stderr, args...); err != nil {
+			return parser.explain(err)
+		}
+
+		return nil
+	}
+
+	ptmx, tty, err := pty.Open()
+	if err != nil {
+		logrus.Debugf("Pulling image %s: failed to open a pseudo-terminal: %s", imageName, err)
+
+		if err := shell.Run("podman", nil, nil, parser, args...); err != nil {
+			logrus.Debugf("Pulling image %s failed:\n%s", imageName, parser.errorOutput())
+			return parser.explain(err)
+		}
+
+		return nil
+	}
+
+	defer ptmx.Close()
+
+	// Wide enough to not truncate the progress bars
+	if err := pty.Setsize(ptmx, &pty.Winsize{Rows: 24, Cols: 200}); err != nil {
+		logrus.Debugf("Pulling image %s: failed to set the size of the pseudo-terminal: %s", imageName, err)
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		// Reading fails with EIO once the other end is closed and
+		// drained, which is expected
+		io.Copy(parser, ptmx)
+		close(done)
+	}()
+
+	err = shell.Run("podman", nil, nil, tty, args...)
+	tty.Close()
+	<-done
+
+	if err != nil {
+		logrus.Debugf("Pulling image %s failed:\n%s", imageName, parser.errorOutput())
+		return parser.explain(err)
+	}
+
+	return nil
+}
+
 func RemoveContainer(container string, forceDelete bool) error {
 	logrus.Debugf("Removing container %s", container)
 
@@ -332,3 +509,15 @@ func SystemMigrate(ociRuntimeRequired string) error {
 
 	return nil
 }
+
+// Tag is a wrapper around the 'podman tag' command
+func Tag(image, name string) error {
+	logLevelString := LogLevel.String()
+	args := []string{"--log-level", logLevelString, "tag", image, name}
+
+	if err := shell.Run("podman", nil, nil, nil, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package podman_test
+
+import (
+	"testing"
+
+	"github.com/containers/toolbox/pkg/podman"
+	"github.com/containers/toolbox/pkg/shell/shelltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePodman stands in for 'podman load' and 'podman pull'. The latter draws
+// progress bars like Podman does on a terminal, and rejects unsigned images
+// when given a signature policy.
+const fakePodman = `#!/bin/sh
+for last; do :; done
+
+case "$last" in
+    /archives/docker.tar)
+        echo "Loaded image(s): registry.example.com/fedora-toolbox:34,registry.example.com/fedora-toolbox:latest"
+        ;;
+    /archives/oci.tar)
+        echo "Getting image source signatures"
+        echo "Loaded image: sha256:9e1c9f0ae0c2b5a3bd0e0ff4bf8e8d6b1d6d2d4e2f8e2b2d8a3c1d3b2c4e5f6a"
+        ;;
+    /archives/empty.tar)
+        ;;
+    registry.example.com/fedora-toolbox:34)
+        echo "Trying to pull $last..." >&2
+        printf 'Copying blob 4f4fb700ef54 [==>-------] 1.0MiB / 4.0MiB\r' >&2
+        printf '\033[1A\033[JCopying blob 4f4fb700ef54 [====>-----] 2.0MiB / 4.0MiB\n' >&2
+        printf 'Copying blob 8d2f0c4a1b2c [=====>----] 512.0KiB / 1.0MiB\n' >&2
+        printf 'Copying blob 8d2f0c4a1b2c done\n' >&2
+        printf 'Copying blob 4f4fb700ef54 done\n' >&2
+        printf 'Copying blob 1a2b3c4d5e6f skipped: already exists\n' >&2
+        printf 'Copying config 9e1c9f0ae0 done\n' >&2
+        echo "Writing manifest to image destination" >&2
+        ;;
+    registry.example.com/unsigned:latest)
+        case "$*" in
+            *"--signature-policy /etc/containers/policy.json"*)
+                echo "Trying to pull $last..." >&2
+                echo "Error: Source image rejected: A signature was required, but no signature exists" >&2
+                exit 125
+                ;;
+        esac
+        ;;
+    *)
+        echo "Error: $last: no such image" >&2
+        exit 125
+        ;;
+esac
+`
+
+func TestLoad(t *testing.T) {
+	defer shelltest.FakeCommand(t, "podman", fakePodman)()
+
+	images, err := podman.Load("/archives/docker.tar")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"registry.example.com/fedora-toolbox:34",
+		"registry.example.com/fedora-toolbox:latest",
+	}, images)
+
+	images, err = podman.Load("/archives/oci.tar")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sha256:9e1c9f0ae0c2b5a3bd0e0ff4bf8e8d6b1d6d2d4e2f8e2b2d8a3c1d3b2c4e5f6a"}, images)
+
+	_, err = podman.Load("/archives/empty.tar")
+	assert.Error(t, err)
+
+	_, err = podman.Load("/archives/missing.tar")
+	assert.Error(t, err)
+}
+
+func TestPullProgress(t *testing.T) {
+	defer shelltest.FakeCommand(t, "podman", fakePodman)()
+
+	var updates []podman.PullProgress
+
+	err := podman.Pull("registry.example.com/fedora-toolbox:34", "", func(progress podman.PullProgress) {
+		updates = append(updates, progress)
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, updates)
+
+	assert.Equal(t, podman.PullProgress{Blobs: 1, Current: 1 << 20, Total: 4 << 20}, updates[0])
+
+	last := updates[len(updates)-1]
+	assert.Equal(t, podman.PullProgress{Blobs: 3, BlobsDone: 3, Current: 5 << 20, Total: 5 << 20}, last)
+
+	err = podman.Pull("registry.example.com/missing:latest", "", func(progress podman.PullProgress) {})
+	assert.EqualError(t, err, "registry.example.com/missing:latest: no such image")
+}
+
+func TestPullSignaturePolicy(t *testing.T) {
+	defer shelltest.FakeCommand(t, "podman", fakePodman)()
+
+	err := podman.Pull("registry.example.com/unsigned:latest", "", nil)
+	assert.NoError(t, err)
+
+	err = podman.Pull("registry.example.com/unsigned:latest", "/etc/containers/policy.json", nil)
+	assert.EqualError(t, err, "Source image rejected: A signature was required, but no signature exists")
+}
@@ -0,0 +1,207 @@
+/*
+ * Copyright © 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package podman
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PullProgress is the progress of pulling an image, aggregated over all its
+// blobs. Current and Total are in bytes, and Total is 0 until the sizes of
+// the blobs are known.
+type PullProgress struct {
+	Blobs     int
+	BlobsDone int
+	Current   int64
+	Total     int64
+}
+
+type pullBlob struct {
+	current int64
+	done    bool
+	total   int64
+}
+
+// pullProgressParser turns the progress output of 'podman pull' into
+// PullProgress updates. It understands both the progress bars shown on
+// terminals, like:
+//
+//	Copying blob 4f4fb700ef54 [=====>------] 10.5MiB / 35.3MiB
+//	Copying blob 4f4fb700ef54 done
+//	Copying blob 4f4fb700ef54 skipped: already exists
+//
+// and the lines shown otherwise, like:
+//
+//	Copying blob sha256:4f4fb700ef54...
+type pullProgressParser struct {
+	blobs    map[string]*pullBlob
+	buffer   bytes.Buffer
+	callback func(PullProgress)
+	lines    []string
+}
+
+var (
+	pullProgressANSIRegexp = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+	pullProgressBlobRegexp = regexp.MustCompile(`^Copying blob (?:sha256:)?([0-9a-f]+)(.*)$`)
+
+	pullProgressCountersRegexp = regexp.MustCompile(
+		`([0-9]+(?:\.[0-9]+)?) ?(b|B|KiB|MiB|GiB|TiB) / ([0-9]+(?:\.[0-9]+)?) ?(b|B|KiB|MiB|GiB|TiB)`)
+
+	pullProgressUnits = map[string]float64{
+		"b":   1,
+		"B":   1,
+		"KiB": 1 << 10,
+		"MiB": 1 << 20,
+		"GiB": 1 << 30,
+		"TiB": 1 << 40,
+	}
+)
+
+// pullProgressMaxLines is how many lines of output, other than progress,
+// are kept to explain failures
+const pullProgressMaxLines = 10
+
+func newPullProgressParser(callback func(PullProgress)) *pullProgressParser {
+	parser := &pullProgressParser{
+		blobs:    make(map[string]*pullBlob),
+		callback: callback,
+	}
+
+	return parser
+}
+
+func (parser *pullProgressParser) Write(p []byte) (int, error) {
+	parser.buffer.Write(p)
+
+	for {
+		data := parser.buffer.Bytes()
+
+		i := bytes.IndexAny(data, "\r\n")
+		if i == -1 {
+			break
+		}
+
+		line := string(data[:i])
+		parser.buffer.Next(i + 1)
+
+		parser.parseLine(line)
+	}
+
+	return len(p), nil
+}
+
+// explain turns err into one that explains why Podman failed, using the last
+// line of its output that looks like an error message.
+func (parser *pullProgressParser) explain(err error) error {
+	for i := len(parser.lines) - 1; i >= 0; i-- {
+		line := parser.lines[i]
+		if !strings.HasPrefix(line, "Error: ") {
+			continue
+		}
+
+		message := strings.TrimPrefix(line, "Error: ")
+		return errors.New(message)
+	}
+
+	return err
+}
+
+// errorOutput returns the most recent lines of output that weren't about
+// progress.
+func (parser *pullProgressParser) errorOutput() string {
+	return strings.Join(parser.lines, "\n")
+}
+
+func (parser *pullProgressParser) parseLine(line string) {
+	line = pullProgressANSIRegexp.ReplaceAllString(line, "")
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+
+	matches := pullProgressBlobRegexp.FindStringSubmatch(line)
+	if matches == nil {
+		if !strings.HasPrefix(line, "Copying ") {
+			parser.lines = append(parser.lines, line)
+			if excess := len(parser.lines) - pullProgressMaxLines; excess > 0 {
+				parser.lines = parser.lines[excess:]
+			}
+		}
+
+		return
+	}
+
+	// Progress bars show shortened digests, while plain lines show
+	// complete ones
+	id := matches[1]
+	if len(id) > 12 {
+		id = id[:12]
+	}
+
+	blob, ok := parser.blobs[id]
+	if !ok {
+		blob = &pullBlob{}
+		parser.blobs[id] = blob
+	}
+
+	status := matches[2]
+
+	if counters := pullProgressCountersRegexp.FindStringSubmatch(status); counters != nil {
+		blob.current = parseSize(counters[1], counters[2])
+		blob.total = parseSize(counters[3], counters[4])
+	}
+
+	if strings.Contains(status, "done") || strings.Contains(status, "skipped") {
+		blob.done = true
+		blob.current = blob.total
+	}
+
+	if parser.callback != nil {
+		parser.callback(parser.progress())
+	}
+}
+
+func (parser *pullProgressParser) progress() PullProgress {
+	var progress PullProgress
+
+	for _, blob := range parser.blobs {
+		progress.Blobs++
+		progress.Current += blob.current
+		progress.Total += blob.total
+
+		if blob.done {
+			progress.BlobsDone++
+		}
+	}
+
+	return progress
+}
+
+func parseSize(value, unit string) int64 {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+
+	size := number * pullProgressUnits[unit]
+	return int64(size)
+}
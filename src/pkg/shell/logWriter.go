@@ -0,0 +1,69 @@
+/*
+ * Copyright © 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package shell
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogWriter logs each line written to it as a separate message. Unlike
+// logrus.Logger.WriterLevel, it logs them as they are written, so nothing is
+// lost or logged late once the writing process has exited.
+type LogWriter struct {
+	buffer []byte
+	level  logrus.Level
+	mutex  sync.Mutex
+}
+
+func NewLogWriter(level logrus.Level) *LogWriter {
+	return &LogWriter{level: level}
+}
+
+func (writer *LogWriter) Write(p []byte) (int, error) {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	writer.buffer = append(writer.buffer, p...)
+
+	for {
+		i := bytes.IndexByte(writer.buffer, '\n')
+		if i == -1 {
+			break
+		}
+
+		logrus.StandardLogger().Log(writer.level, string(writer.buffer[:i]))
+		writer.buffer = writer.buffer[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush logs what's left after the last complete line.
+func (writer *LogWriter) Flush() {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	if len(writer.buffer) == 0 {
+		return
+	}
+
+	logrus.StandardLogger().Log(writer.level, string(writer.buffer))
+	writer.buffer = nil
+}
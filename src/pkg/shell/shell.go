@@ -20,8 +20,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
 	"os/exec"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -40,9 +41,14 @@ func Run(name string, stdin io.Reader, stdout, stderr io.Writer, arg ...string)
 }
 
 func RunWithExitCode(name string, stdin io.Reader, stdout, stderr io.Writer, arg ...string) (int, error) {
+	// The standard error is logged line by line, so that it follows the
+	// format and destination of the log messages
 	logLevel := logrus.GetLevel()
 	if stderr == nil && logLevel >= logrus.DebugLevel {
-		stderr = os.Stderr
+		stderrWriter := NewLogWriter(logrus.DebugLevel)
+		defer stderrWriter.Flush()
+
+		stderr = stderrWriter
 	}
 
 	cmd := exec.Command(name, arg...)
@@ -50,6 +56,29 @@ func RunWithExitCode(name string, stdin io.Reader, stdout, stderr io.Writer, arg
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
+	start := time.Now()
+	exitCode, err := run(cmd, name)
+
+	// Only Podman is logged with structured fields, because the other
+	// commands aren't interesting enough to collect after the fact
+	if name == "podman" {
+		logger := logrus.WithFields(logrus.Fields{
+			"args":     redactArgs(arg),
+			"command":  name,
+			"duration": time.Since(start).String(),
+		})
+
+		if err != nil {
+			logger.WithError(err).Infof("Invoking %s(1) failed", name)
+		} else {
+			logger.WithField("exit_code", exitCode).Infof("Invoked %s(1)", name)
+		}
+	}
+
+	return exitCode, err
+}
+
+func run(cmd *exec.Cmd, name string) (int, error) {
 	if err := cmd.Run(); err != nil {
 		if errors.Is(err, exec.ErrNotFound) {
 			return 1, fmt.Errorf("%s(1) not found", name)
@@ -66,3 +95,37 @@ func RunWithExitCode(name string, stdin io.Reader, stdout, stderr io.Writer, arg
 
 	return 0, nil
 }
+
+// redactArgs returns a copy of args without the values of environment
+// variables, which can hold secrets like passwords in proxy URLs, so that
+// they don't end up in the logs.
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	redactNext := false
+
+	for i, arg := range args {
+		switch {
+		case redactNext:
+			redacted[i] = redactEnvironmentVariable(arg)
+			redactNext = false
+		case arg == "--env" || arg == "-e":
+			redacted[i] = arg
+			redactNext = true
+		case strings.HasPrefix(arg, "--env="):
+			redacted[i] = "--env=" + redactEnvironmentVariable(strings.TrimPrefix(arg, "--env="))
+		default:
+			redacted[i] = arg
+		}
+	}
+
+	return redacted
+}
+
+func redactEnvironmentVariable(variable string) string {
+	i := strings.IndexByte(variable, '=')
+	if i == -1 {
+		return variable
+	}
+
+	return variable[:i+1] + "<redacted>"
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/containers/toolbox/pkg/shell"
 	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -225,6 +226,32 @@ func TestShellRunWithExitCode(t *testing.T) {
 	}
 }
 
+func TestLogWriter(t *testing.T) {
+	hook := test.NewGlobal()
+	defer hook.Reset()
+
+	logrus.SetLevel(logrus.DebugLevel)
+
+	writer := shell.NewLogWriter(logrus.DebugLevel)
+	writer.Write([]byte("first line\nsecond "))
+	writer.Write([]byte("line\nlast line"))
+
+	entries := hook.AllEntries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "first line", entries[0].Message)
+	assert.Equal(t, "second line", entries[1].Message)
+	assert.Equal(t, logrus.DebugLevel, entries[1].Level)
+
+	writer.Flush()
+
+	entries = hook.AllEntries()
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "last line", entries[2].Message)
+
+	writer.Flush()
+	assert.Len(t, hook.AllEntries(), 3)
+}
+
 // outputMock is a mock to ensure content written to stdout/stderr was correct
 type outputMock struct {
 	written []byte
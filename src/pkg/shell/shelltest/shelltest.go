@@ -0,0 +1,55 @@
+/*
+ * Copyright © 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package shelltest helps to test code that runs commands through package
+// shell, by standing in for those commands with scripts.
+package shelltest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// FakeCommand makes script the command called name, by putting it in a
+// temporary directory at the front of $PATH. The returned function restores
+// $PATH and removes the directory.
+func FakeCommand(t *testing.T, name, script string) func() {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "toolbox-test-"+name+"-")
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(script), 0755); err != nil {
+		os.RemoveAll(dir)
+		require.NoError(t, err)
+	}
+
+	oldPath := os.Getenv("PATH")
+	if err := os.Setenv("PATH", dir+string(os.PathListSeparator)+oldPath); err != nil {
+		os.RemoveAll(dir)
+		require.NoError(t, err)
+	}
+
+	return func() {
+		os.Setenv("PATH", oldPath)
+		os.RemoveAll(dir)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright © 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package signature
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/containers/toolbox/pkg/shell"
+	"github.com/sirupsen/logrus"
+)
+
+type cosignSignature struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// VerifyCosign is a wrapper around the 'cosign verify' command. It checks that
+// an image in a registry is signed with the private key matching the public
+// key at path key, and returns the digest of the signed image. The error says
+// why the image was rejected, if that can be found out.
+func VerifyCosign(image, key string) (string, error) {
+	var stderr bytes.Buffer
+	var stdout bytes.Buffer
+
+	logrus.Debugf("Verifying the signature of image %s with key %s", image, key)
+
+	args := []string{"verify", "--key", key, "--output", "json", image}
+
+	if err := shell.Run("cosign", nil, &stdout, &stderr, args...); err != nil {
+		logrus.Debugf("Verifying the signature of image %s failed:\n%s", image, stderr.String())
+
+		if message := cosignErrorMessage(stderr.String()); message != "" {
+			return "", errors.New(message)
+		}
+
+		return "", err
+	}
+
+	var signatures []cosignSignature
+	if err := json.Unmarshal(stdout.Bytes(), &signatures); err != nil {
+		return "", fmt.Errorf("failed to parse the output of cosign(1): %w", err)
+	}
+
+	if len(signatures) == 0 {
+		return "", errors.New("cosign(1) didn't report any signatures")
+	}
+
+	digest := signatures[0].Critical.Image.DockerManifestDigest
+	if digest == "" {
+		return "", errors.New("cosign(1) didn't report a digest")
+	}
+
+	for _, signature := range signatures[1:] {
+		if signature.Critical.Image.DockerManifestDigest != digest {
+			return "", errors.New("cosign(1) reported signatures for different digests")
+		}
+	}
+
+	return digest, nil
+}
+
+func cosignErrorMessage(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(line, "Error: ") {
+			message := strings.TrimPrefix(line, "Error: ")
+			return strings.TrimSuffix(message, ":")
+		}
+	}
+
+	return ""
+}
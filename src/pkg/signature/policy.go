@@ -0,0 +1,155 @@
+/*
+ * Copyright © 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package signature
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// RequirementAcceptAnything accepts images without checking them
+	RequirementAcceptAnything = "insecureAcceptAnything"
+
+	systemPolicyFile = "/etc/containers/policy.json"
+)
+
+// Requirement is one of the requirements that a policy places on images.
+// Only the type is needed to tell whether signatures are required.
+type Requirement struct {
+	Type string `json:"type"`
+}
+
+// Policy is a signature verification policy, as described in
+// containers-policy.json(5).
+type Policy struct {
+	Default    []Requirement                       `json:"default"`
+	Transports map[string]map[string][]Requirement `json:"transports"`
+
+	path string
+}
+
+// AcceptsUnsigned tells whether images that aren't signed satisfy all the
+// requirements.
+func AcceptsUnsigned(requirements []Requirement) bool {
+	for _, requirement := range requirements {
+		if requirement.Type != RequirementAcceptAnything {
+			return false
+		}
+	}
+
+	return true
+}
+
+// DefaultPolicyPath returns the policy used by Podman when none is specified,
+// which is the one in the user's configuration directory, if it exists, or
+// the system-wide one.
+func DefaultPolicyPath() string {
+	if configDir, err := os.UserConfigDir(); err == nil {
+		userPolicyFile := filepath.Join(configDir, "containers", "policy.json")
+		if _, err := os.Stat(userPolicyFile); err == nil {
+			return userPolicyFile
+		}
+	} else {
+		logrus.Debugf("Looking for signature policy: failed to get the user config directory: %s", err)
+	}
+
+	return systemPolicyFile
+}
+
+// LoadPolicy reads the signature verification policy at path.
+func LoadPolicy(path string) (*Policy, error) {
+	logrus.Debugf("Loading signature policy %s", path)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature policy %s: %w", path, err)
+	}
+
+	policy := &Policy{path: path}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("failed to parse signature policy %s: %w", path, err)
+	}
+
+	if policy.Default == nil {
+		return nil, fmt.Errorf("signature policy %s has no default requirements", path)
+	}
+
+	return policy, nil
+}
+
+func (policy *Policy) Path() string {
+	return policy.path
+}
+
+// Requirements returns the requirements that apply to an image in a registry,
+// along with the scope that they were found in. Like containers/image, the
+// most specific scope wins:
+//
+//	registry.example.com/namespace/image:tag
+//	registry.example.com/namespace/image
+//	registry.example.com/namespace
+//	registry.example.com
+//	*.example.com
+//	*.com
+//
+// followed by the default for the docker transport, and the default of the
+// policy.
+func (policy *Policy) Requirements(reference *utils.ImageReference) (string, []Requirement) {
+	scopes := policy.Transports["docker"]
+
+	var candidates []string
+
+	name := reference.Name()
+	if reference.Digest != "" {
+		candidates = append(candidates, name+"@"+reference.Digest)
+	} else {
+		candidates = append(candidates, name+":"+reference.TagOrDefault())
+	}
+
+	for scope := name; strings.Contains(scope, "/"); scope = scope[:strings.LastIndex(scope, "/")] {
+		candidates = append(candidates, scope)
+	}
+
+	host := reference.Domain
+	candidates = append(candidates, host)
+
+	if i := strings.IndexRune(host, ':'); i != -1 {
+		host = host[:i]
+	}
+
+	for labels := strings.Split(host, ".")[1:]; len(labels) != 0; labels = labels[1:] {
+		candidates = append(candidates, "*."+strings.Join(labels, "."))
+	}
+
+	candidates = append(candidates, "")
+
+	for _, candidate := range candidates {
+		if requirements, ok := scopes[candidate]; ok {
+			return candidate, requirements
+		}
+	}
+
+	return "default", policy.Default
+}
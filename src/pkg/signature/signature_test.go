@@ -0,0 +1,116 @@
+/*
+ * Copyright © 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package signature_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containers/toolbox/pkg/shell/shelltest"
+	"github.com/containers/toolbox/pkg/signature"
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `{
+    "default": [{"type": "insecureAcceptAnything"}],
+    "transports": {
+        "docker": {
+            "registry.example.com/signed": [{"type": "signedBy", "keyType": "GPGKeys", "keyPath": "/etc/pki/example.gpg"}],
+            "registry.example.com/signed/unsigned:34": [{"type": "insecureAcceptAnything"}],
+            "registry.example.com:5000": [{"type": "reject"}],
+            "*.example.org": [{"type": "sigstoreSigned", "keyPath": "/etc/pki/example.pub"}]
+        },
+        "docker-daemon": {
+            "": [{"type": "insecureAcceptAnything"}]
+        }
+    }
+}`
+
+// fakeCosign stands in for 'cosign verify', and accepts only images from
+// registry.example.com/signed.
+const fakeCosign = `#!/bin/sh
+for last; do :; done
+
+case "$last" in
+    registry.example.com/signed/*)
+        echo '[{"critical":{"identity":{"docker-reference":"registry.example.com/signed"},"image":{"docker-manifest-digest":"sha256:2c1f8f1e8f3e1b5cbb7b6bd4b6a6b9fdd7bda1f3c0a7c0e9a2d2f0a6a9c4d1e3"},"type":"cosign container image signature"},"optional":null}]'
+        ;;
+    *)
+        echo "Error: no matching signatures:" >&2
+        echo "main.go:62: error during command execution: no matching signatures:" >&2
+        exit 1
+        ;;
+esac
+`
+
+func TestPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "toolbox-signature-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "policy.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(testPolicy), 0644))
+
+	policy, err := signature.LoadPolicy(path)
+	require.NoError(t, err)
+	assert.Equal(t, path, policy.Path())
+
+	testCases := []struct {
+		image           string
+		scope           string
+		acceptsUnsigned bool
+	}{
+		{"registry.example.com/signed/fedora-toolbox:34", "registry.example.com/signed", false},
+		{"registry.example.com/signed/unsigned:34", "registry.example.com/signed/unsigned:34", true},
+		{"registry.example.com/signed/unsigned", "registry.example.com/signed", false},
+		{"registry.example.com/fedora-toolbox:34", "default", true},
+		{"registry.example.com:5000/fedora-toolbox:34", "registry.example.com:5000", false},
+		{"registry.example.org/fedora-toolbox:34", "*.example.org", false},
+		{"quay.io/example/fedora-toolbox:34", "default", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			reference, err := utils.ParseImageReference(tc.image)
+			require.NoError(t, err)
+
+			scope, requirements := policy.Requirements(reference)
+			assert.Equal(t, tc.scope, scope)
+			assert.Equal(t, tc.acceptsUnsigned, signature.AcceptsUnsigned(requirements))
+		})
+	}
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"transports": {}}`), 0644))
+
+	_, err = signature.LoadPolicy(path)
+	assert.Error(t, err)
+}
+
+func TestVerifyCosign(t *testing.T) {
+	defer shelltest.FakeCommand(t, "cosign", fakeCosign)()
+
+	digest, err := signature.VerifyCosign("registry.example.com/signed/fedora-toolbox:34", "/etc/pki/example.pub")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:2c1f8f1e8f3e1b5cbb7b6bd4b6a6b9fdd7bda1f3c0a7c0e9a2d2f0a6a9c4d1e3", digest)
+
+	_, err = signature.VerifyCosign("registry.example.com/fedora-toolbox:34", "/etc/pki/example.pub")
+	assert.EqualError(t, err, "no matching signatures")
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright © 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package skopeo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/containers/toolbox/pkg/shell"
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Image holds the information about an image in a registry that's relevant
+// to Toolbox.
+type Image struct {
+	Name   string
+	Digest string
+	Labels map[string]string
+}
+
+// manifest holds the fields of an image manifest, or of a manifest list, that
+// are needed to find the size of an image. Both the Docker and the OCI formats
+// are covered.
+type manifest struct {
+	Config struct {
+		Size int64
+	}
+
+	Layers []struct {
+		Size int64
+	}
+
+	Manifests []struct {
+		Digest   string
+		Platform struct {
+			Architecture string
+			OS           string
+		}
+	}
+}
+
+// GetSize returns the compressed size of an image in a container registry,
+// that is, the amount of data that needs to be downloaded to pull it. If the
+// image is a manifest list, the size of the image for the host's architecture
+// is returned.
+func GetSize(image string, timeout time.Duration) (int64, error) {
+	rawManifest, err := InspectRaw("docker://"+image, timeout)
+	if err != nil {
+		return 0, err
+	}
+
+	var imageManifest manifest
+	if err := json.Unmarshal(rawManifest, &imageManifest); err != nil {
+		return 0, fmt.Errorf("failed to parse the manifest of image %s: %w", image, err)
+	}
+
+	if len(imageManifest.Manifests) != 0 {
+		var digest string
+
+		for _, instance := range imageManifest.Manifests {
+			if instance.Platform.OS == "linux" && instance.Platform.Architecture == runtime.GOARCH {
+				digest = instance.Digest
+				break
+			}
+		}
+
+		if digest == "" {
+			return 0, fmt.Errorf("image %s is not available for architecture %s", image, runtime.GOARCH)
+		}
+
+		logrus.Debugf("Image %s for architecture %s is %s", image, runtime.GOARCH, digest)
+
+		// References with both a tag and a digest aren't supported by
+		// skopeo(1)
+		reference, err := utils.ParseImageReference(image)
+		if err != nil {
+			return 0, err
+		}
+
+		imageName := reference.Name()
+
+		rawManifest, err = InspectRaw("docker://"+imageName+"@"+digest, timeout)
+		if err != nil {
+			return 0, err
+		}
+
+		imageManifest = manifest{}
+		if err := json.Unmarshal(rawManifest, &imageManifest); err != nil {
+			return 0, fmt.Errorf("failed to parse the manifest of image %s: %w", image, err)
+		}
+	}
+
+	if len(imageManifest.Layers) == 0 {
+		return 0, fmt.Errorf("manifest of image %s doesn't list any layers", image)
+	}
+
+	size := imageManifest.Config.Size
+	for _, layer := range imageManifest.Layers {
+		size += layer.Size
+	}
+
+	return size, nil
+}
+
+// Inspect is a wrapper around the 'skopeo inspect' command for images in
+// container registries.
+//
+// Parameter image is a fully qualified image reference without a transport,
+// like registry.fedoraproject.org/fedora-toolbox:34. Parameter timeout is the
+// longest time that skopeo(1) may take, or 0 for no limit.
+func Inspect(image string, timeout time.Duration) (*Image, error) {
+	var stdout bytes.Buffer
+
+	args := []string{}
+
+	if timeout > 0 {
+		args = append(args, "--command-timeout", timeout.String())
+	}
+
+	if logLevel := logrus.GetLevel(); logLevel >= logrus.DebugLevel {
+		args = append(args, "--debug")
+	}
+
+	args = append(args, "inspect", "docker://"+image)
+
+	if err := shell.Run("skopeo", nil, &stdout, nil, args...); err != nil {
+		return nil, err
+	}
+
+	output := stdout.Bytes()
+	var info Image
+
+	if err := json.Unmarshal(output, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse the output of skopeo(1): %w", err)
+	}
+
+	if info.Digest == "" {
+		return nil, errors.New("skopeo(1) didn't report a digest")
+	}
+
+	return &info, nil
+}
+
+// InspectRaw is a wrapper around the 'skopeo inspect --raw' command, and
+// returns the manifest or manifest list of an image without interpreting it.
+//
+// Parameter image is an image reference with a transport, like
+// docker://registry.fedoraproject.org/fedora-toolbox:34.
+func InspectRaw(image string, timeout time.Duration) ([]byte, error) {
+	var stdout bytes.Buffer
+
+	args := []string{}
+
+	if timeout > 0 {
+		args = append(args, "--command-timeout", timeout.String())
+	}
+
+	if logLevel := logrus.GetLevel(); logLevel >= logrus.DebugLevel {
+		args = append(args, "--debug")
+	}
+
+	args = append(args, "inspect", "--raw", image)
+
+	if err := shell.Run("skopeo", nil, &stdout, nil, args...); err != nil {
+		return nil, err
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,83 @@
+package skopeo_test
+
+import (
+	"testing"
+
+	"github.com/containers/toolbox/pkg/shell/shelltest"
+	"github.com/containers/toolbox/pkg/skopeo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistry stands in for skopeo(1) talking to a registry, and knows about
+// a single image.
+const fakeRegistry = `#!/bin/sh
+raw=false
+for arg; do
+    [ "$arg" = "--raw" ] && raw=true
+    last="$arg"
+done
+
+case "$raw $last" in
+    "false docker://registry.example.com/fedora-toolbox:34")
+        echo '{"Name": "registry.example.com/fedora-toolbox", "Digest": "sha256:1234", "Labels": {"com.github.containers.toolbox": "true"}}'
+        ;;
+    "false docker://registry.example.com/broken:latest")
+        echo '{"Name": "registry.example.com/broken"}'
+        ;;
+    "true docker://registry.example.com/fedora-toolbox:34")
+        echo '{"schemaVersion": 2, "manifests": ['
+        for arch in 386 amd64 arm arm64 ppc64le riscv64 s390x; do
+            echo '{"digest": "sha256:'$arch'", "platform": {"architecture": "'$arch'", "os": "linux"}},'
+        done
+        echo '{"digest": "sha256:windows", "platform": {"architecture": "amd64", "os": "windows"}}]}'
+        ;;
+    "true docker://registry.example.com/fedora-toolbox@sha256:"*)
+        echo '{"schemaVersion": 2, "config": {"size": 1000}, "layers": [{"size": 2000}, {"size": 3000}]}'
+        ;;
+    "true docker://registry.example.com/single:latest")
+        echo '{"schemaVersion": 2, "config": {"size": 10}, "layers": [{"size": 20}]}'
+        ;;
+    "true docker://registry.example.com/schema1:latest")
+        echo '{"schemaVersion": 1, "fsLayers": [{"blobSum": "sha256:5678"}]}'
+        ;;
+    *)
+        echo "Error: manifest unknown" >&2
+        exit 1
+        ;;
+esac
+`
+
+func TestInspect(t *testing.T) {
+	defer shelltest.FakeCommand(t, "skopeo", fakeRegistry)()
+
+	image, err := skopeo.Inspect("registry.example.com/fedora-toolbox:34", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "registry.example.com/fedora-toolbox", image.Name)
+	assert.Equal(t, "sha256:1234", image.Digest)
+	assert.Equal(t, "true", image.Labels["com.github.containers.toolbox"])
+
+	_, err = skopeo.Inspect("registry.example.com/broken:latest", 0)
+	assert.Error(t, err)
+
+	_, err = skopeo.Inspect("registry.example.com/missing:latest", 0)
+	assert.Error(t, err)
+}
+
+func TestGetSize(t *testing.T) {
+	defer shelltest.FakeCommand(t, "skopeo", fakeRegistry)()
+
+	size, err := skopeo.GetSize("registry.example.com/fedora-toolbox:34", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6000), size)
+
+	size, err = skopeo.GetSize("registry.example.com/single:latest", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(30), size)
+
+	_, err = skopeo.GetSize("registry.example.com/schema1:latest", 0)
+	assert.Error(t, err)
+
+	_, err = skopeo.GetSize("registry.example.com/missing:latest", 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,558 @@
+/*
+ * Copyright © 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package users
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containers/toolbox/pkg/shell"
+	"github.com/sirupsen/logrus"
+)
+
+// Group describes a group inside a toolbox container.
+type Group struct {
+	Name string
+	GID  int
+}
+
+// User describes a user account inside a toolbox container.
+type User struct {
+	Name   string
+	UID    int
+	GID    int
+	Home   string
+	Shell  string
+	Groups []string
+}
+
+// Manager adds and modifies user accounts using whatever the operating
+// system inside the container provides.
+type Manager interface {
+	// AddGroup adds a new group with the given numerical group ID.
+	AddGroup(name string, gid int) error
+
+	// AddUser adds a new user and makes it a member of user.Groups.
+	AddUser(user *User) error
+
+	// DeletePassword makes the account with the given name passwordless.
+	DeletePassword(name string) error
+
+	// ModifyUser updates an existing user and appends user.Groups to its
+	// supplementary groups.
+	ModifyUser(user *User) error
+
+	// Name returns a human readable name of the mechanism being used.
+	Name() string
+}
+
+type shadowUtilsManager struct{}
+
+type busyBoxManager struct {
+	files *filesManager
+}
+
+type filesManager struct {
+	root string
+}
+
+// NewManager returns the most capable Manager available.
+//
+// The tools from shadow-utils are preferred, followed by the adduser(1) and
+// addgroup(1) applets from BusyBox. As a last resort /etc/passwd, /etc/group
+// and /etc/shadow are edited directly.
+func NewManager() Manager {
+	if commandsExist("groupadd", "useradd", "usermod", "passwd") {
+		logrus.Debug("Managing users with shadow-utils")
+		return &shadowUtilsManager{}
+	}
+
+	if commandsExist("adduser", "addgroup", "passwd") {
+		logrus.Debug("Managing users with adduser(1) and addgroup(1)")
+		return &busyBoxManager{files: &filesManager{root: "/"}}
+	}
+
+	logrus.Debug("Managing users by editing /etc/passwd, /etc/group and /etc/shadow")
+	return NewFilesManager("/")
+}
+
+// NewFilesManager returns a Manager that directly edits the account databases
+// in the /etc directory under root.
+func NewFilesManager(root string) Manager {
+	return &filesManager{root: root}
+}
+
+// ConfigureAdmin grants the user root access through sudo(8) or doas(1) with a
+// drop-in file. It's meant for containers that don't have a group for
+// administrators.
+func ConfigureAdmin(name string) error {
+	if _, err := exec.LookPath("sudo"); err == nil || pathExists("/etc/sudoers.d") {
+		logrus.Debugf("Granting user %s root access through sudo(8)", name)
+
+		if err := os.MkdirAll("/etc/sudoers.d", 0750); err != nil {
+			return fmt.Errorf("failed to create /etc/sudoers.d: %w", err)
+		}
+
+		rule := fmt.Sprintf("# Written by Toolbox\n%s ALL=(ALL) NOPASSWD: ALL\n", name)
+		if err := ioutil.WriteFile("/etc/sudoers.d/toolbox", []byte(rule), 0440); err != nil {
+			return fmt.Errorf("failed to write /etc/sudoers.d/toolbox: %w", err)
+		}
+
+		return nil
+	}
+
+	if _, err := exec.LookPath("doas"); err == nil {
+		logrus.Debugf("Granting user %s root access through doas(1)", name)
+
+		rule := fmt.Sprintf("# Written by Toolbox\npermit nopass %s as root\n", name)
+
+		if pathExists("/etc/doas.d") {
+			if err := ioutil.WriteFile("/etc/doas.d/toolbox.conf", []byte(rule), 0600); err != nil {
+				return fmt.Errorf("failed to write /etc/doas.d/toolbox.conf: %w", err)
+			}
+
+			return nil
+		}
+
+		doasConf, err := ioutil.ReadFile("/etc/doas.conf")
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read /etc/doas.conf: %w", err)
+		}
+
+		if strings.Contains(string(doasConf), rule) {
+			return nil
+		}
+
+		doasConf = append(doasConf, []byte(rule)...)
+		if err := ioutil.WriteFile("/etc/doas.conf", doasConf, 0600); err != nil {
+			return fmt.Errorf("failed to write /etc/doas.conf: %w", err)
+		}
+
+		return nil
+	}
+
+	return errors.New("neither sudo(8) nor doas(1) found")
+}
+
+func (m *shadowUtilsManager) AddGroup(name string, gid int) error {
+	logrus.Debugf("Adding group %s with GID %d", name, gid)
+
+	if err := shell.Run("groupadd", nil, nil, nil, "--gid", fmt.Sprint(gid), name); err != nil {
+		return fmt.Errorf("failed to add group %s with GID %d", name, gid)
+	}
+
+	return nil
+}
+
+func (m *shadowUtilsManager) AddUser(user *User) error {
+	logrus.Debugf("Adding user %s with UID %d:", user.Name, user.UID)
+
+	var useraddArgs []string
+
+	if len(user.Groups) != 0 {
+		useraddArgs = append(useraddArgs, "--groups", strings.Join(user.Groups, ","))
+	}
+
+	useraddArgs = append(useraddArgs, []string{
+		"--home-dir", user.Home,
+		"--no-create-home",
+		"--shell", user.Shell,
+		"--uid", fmt.Sprint(user.UID),
+		user.Name,
+	}...)
+
+	logrus.Debug("useradd")
+	for _, arg := range useraddArgs {
+		logrus.Debugf("%s", arg)
+	}
+
+	if err := shell.Run("useradd", nil, nil, nil, useraddArgs...); err != nil {
+		return fmt.Errorf("failed to add user %s with UID %d", user.Name, user.UID)
+	}
+
+	return nil
+}
+
+func (m *shadowUtilsManager) DeletePassword(name string) error {
+	if err := shell.Run("passwd", nil, nil, nil, "--delete", name); err != nil {
+		return fmt.Errorf("failed to remove password for user %s", name)
+	}
+
+	return nil
+}
+
+func (m *shadowUtilsManager) ModifyUser(user *User) error {
+	logrus.Debugf("Modifying user %s with UID %d:", user.Name, user.UID)
+
+	var usermodArgs []string
+
+	if len(user.Groups) != 0 {
+		usermodArgs = append(usermodArgs, "--append", "--groups", strings.Join(user.Groups, ","))
+	}
+
+	usermodArgs = append(usermodArgs, []string{
+		"--home", user.Home,
+		"--shell", user.Shell,
+		"--uid", fmt.Sprint(user.UID),
+		user.Name,
+	}...)
+
+	logrus.Debug("usermod")
+	for _, arg := range usermodArgs {
+		logrus.Debugf("%s", arg)
+	}
+
+	if err := shell.Run("usermod", nil, nil, nil, usermodArgs...); err != nil {
+		return fmt.Errorf("failed to modify user %s with UID %d", user.Name, user.UID)
+	}
+
+	return nil
+}
+
+func (m *shadowUtilsManager) Name() string {
+	return "shadow-utils"
+}
+
+func (m *busyBoxManager) AddGroup(name string, gid int) error {
+	logrus.Debugf("Adding group %s with GID %d", name, gid)
+
+	if err := shell.Run("addgroup", nil, nil, nil, "-g", fmt.Sprint(gid), name); err != nil {
+		return fmt.Errorf("failed to add group %s with GID %d", name, gid)
+	}
+
+	return nil
+}
+
+func (m *busyBoxManager) AddUser(user *User) error {
+	logrus.Debugf("Adding user %s with UID %d:", user.Name, user.UID)
+
+	adduserArgs := []string{
+		"-D",
+		"-H",
+		"-h", user.Home,
+		"-s", user.Shell,
+		"-u", fmt.Sprint(user.UID),
+		user.Name,
+	}
+
+	logrus.Debug("adduser")
+	for _, arg := range adduserArgs {
+		logrus.Debugf("%s", arg)
+	}
+
+	if err := shell.Run("adduser", nil, nil, nil, adduserArgs...); err != nil {
+		return fmt.Errorf("failed to add user %s with UID %d", user.Name, user.UID)
+	}
+
+	for _, group := range user.Groups {
+		logrus.Debugf("Adding user %s to group %s", user.Name, group)
+
+		if err := shell.Run("addgroup", nil, nil, nil, user.Name, group); err != nil {
+			return fmt.Errorf("failed to add user %s to group %s", user.Name, group)
+		}
+	}
+
+	return nil
+}
+
+func (m *busyBoxManager) DeletePassword(name string) error {
+	if err := shell.Run("passwd", nil, nil, nil, "-d", name); err != nil {
+		return fmt.Errorf("failed to remove password for user %s", name)
+	}
+
+	return nil
+}
+
+// ModifyUser falls back to editing the files, because BusyBox doesn't have an
+// equivalent of usermod(8).
+func (m *busyBoxManager) ModifyUser(user *User) error {
+	return m.files.ModifyUser(user)
+}
+
+func (m *busyBoxManager) Name() string {
+	return "adduser"
+}
+
+func (m *filesManager) AddGroup(name string, gid int) error {
+	logrus.Debugf("Adding group %s with GID %d to %s", name, gid, m.path("/etc/group"))
+
+	groupEntry := fmt.Sprintf("%s:x:%d:", name, gid)
+	if err := m.appendEntry("/etc/group", groupEntry); err != nil {
+		return err
+	}
+
+	if pathExists(m.path("/etc/gshadow")) {
+		gshadowEntry := fmt.Sprintf("%s:!::", name)
+		if err := m.appendEntry("/etc/gshadow", gshadowEntry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *filesManager) AddUser(user *User) error {
+	logrus.Debugf("Adding user %s with UID %d to %s", user.Name, user.UID, m.path("/etc/passwd"))
+
+	groupFields, err := m.findEntry("/etc/group", func(fields []string) bool {
+		return fields[2] == strconv.Itoa(user.GID)
+	})
+	if err != nil {
+		return err
+	}
+
+	if groupFields == nil {
+		if err := m.AddGroup(user.Name, user.GID); err != nil {
+			return err
+		}
+	}
+
+	password := ""
+	if pathExists(m.path("/etc/shadow")) {
+		password = "x"
+
+		days := time.Now().Unix() / (24 * 60 * 60)
+		shadowEntry := fmt.Sprintf("%s::%d:0:99999:7:::", user.Name, days)
+		if err := m.appendEntry("/etc/shadow", shadowEntry); err != nil {
+			return err
+		}
+	}
+
+	passwdEntry := fmt.Sprintf("%s:%s:%d:%d::%s:%s",
+		user.Name,
+		password,
+		user.UID,
+		user.GID,
+		user.Home,
+		user.Shell)
+
+	if err := m.appendEntry("/etc/passwd", passwdEntry); err != nil {
+		return err
+	}
+
+	if err := m.addToGroups(user.Name, user.Groups); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *filesManager) DeletePassword(name string) error {
+	file := "/etc/passwd"
+	if pathExists(m.path("/etc/shadow")) {
+		file = "/etc/shadow"
+	}
+
+	if err := m.updateEntry(file, name, func(fields []string) {
+		fields[1] = ""
+	}); err != nil {
+		return fmt.Errorf("failed to remove password for user %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func (m *filesManager) ModifyUser(user *User) error {
+	logrus.Debugf("Modifying user %s with UID %d in %s", user.Name, user.UID, m.path("/etc/passwd"))
+
+	if err := m.updateEntry("/etc/passwd", user.Name, func(fields []string) {
+		if len(fields) == 7 {
+			fields[2] = strconv.Itoa(user.UID)
+			fields[5] = user.Home
+			fields[6] = user.Shell
+		}
+	}); err != nil {
+		return fmt.Errorf("failed to modify user %s with UID %d: %w", user.Name, user.UID, err)
+	}
+
+	if err := m.addToGroups(user.Name, user.Groups); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *filesManager) Name() string {
+	return "files"
+}
+
+func (m *filesManager) addToGroups(name string, groups []string) error {
+	for _, group := range groups {
+		logrus.Debugf("Adding user %s to group %s", name, group)
+
+		if err := m.updateEntry("/etc/group", group, func(fields []string) {
+			members := splitMembers(fields[3])
+			for _, member := range members {
+				if member == name {
+					return
+				}
+			}
+
+			members = append(members, name)
+			fields[3] = strings.Join(members, ",")
+		}); err != nil {
+			return fmt.Errorf("failed to add user %s to group %s: %w", name, group, err)
+		}
+	}
+
+	return nil
+}
+
+func (m *filesManager) appendEntry(file, entry string) error {
+	data, err := ioutil.ReadFile(m.path(file))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	if len(data) != 0 && data[len(data)-1] != '\n' {
+		data = append(data, '\n')
+	}
+
+	data = append(data, []byte(entry+"\n")...)
+	return m.writeFile(file, data)
+}
+
+func (m *filesManager) findEntry(file string, match func(fields []string) bool) ([]string, error) {
+	data, err := ioutil.ReadFile(m.path(file))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 3 {
+			continue
+		}
+
+		if match(fields) {
+			return fields, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (m *filesManager) path(file string) string {
+	return filepath.Join(m.root, file)
+}
+
+// updateEntry rewrites the entry of file whose first field is name with
+// update.
+func (m *filesManager) updateEntry(file, name string, update func(fields []string)) error {
+	data, err := ioutil.ReadFile(m.path(file))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	var found bool
+
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		fields := strings.Split(line, ":")
+		if fields[0] != name || len(fields) < 4 {
+			continue
+		}
+
+		update(fields)
+		lines[i] = strings.Join(fields, ":")
+		found = true
+		break
+	}
+
+	if !found {
+		return fmt.Errorf("failed to find %s in %s", name, file)
+	}
+
+	if err := m.writeFile(file, []byte(strings.Join(lines, "\n"))); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *filesManager) writeFile(file string, data []byte) error {
+	path := m.path(file)
+
+	mode := os.FileMode(0644)
+	if fileInfo, err := os.Stat(path); err == nil {
+		mode = fileInfo.Mode().Perm()
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+"-")
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", file, err)
+	}
+
+	tmpFileName := tmpFile.Name()
+	defer os.Remove(tmpFileName)
+
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", file, err)
+	}
+
+	if err := os.Chmod(tmpFileName, mode); err != nil {
+		return fmt.Errorf("failed to update %s: %w", file, err)
+	}
+
+	if err := os.Rename(tmpFileName, path); err != nil {
+		return fmt.Errorf("failed to update %s: %w", file, err)
+	}
+
+	return nil
+}
+
+func commandsExist(commands ...string) bool {
+	for _, command := range commands {
+		if _, err := exec.LookPath(command); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func pathExists(path string) bool {
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return true
+	}
+
+	return false
+}
+
+func splitMembers(members string) []string {
+	if members == "" {
+		return nil
+	}
+
+	return strings.Split(members, ",")
+}
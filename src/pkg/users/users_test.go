@@ -0,0 +1,136 @@
+package users_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/containers/toolbox/pkg/users"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setUpRoot(t *testing.T, files map[string]string) string {
+	root, err := ioutil.TempDir("", "toolbox-users-test-")
+	require.NoError(t, err)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "etc"), 0755))
+
+	for file, content := range files {
+		path := filepath.Join(root, file)
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+
+	return root
+}
+
+func readFile(t *testing.T, root, file string) string {
+	data, err := ioutil.ReadFile(filepath.Join(root, file))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestFilesManagerAddUser(t *testing.T) {
+	testCases := []struct {
+		name   string
+		files  map[string]string
+		expect map[string]string
+	}{
+		{
+			name: "WithShadow",
+			files: map[string]string{
+				"/etc/passwd": "root:x:0:0:root:/root:/bin/sh\n",
+				"/etc/group":  "root:x:0:\nwheel:x:10:root\n",
+				"/etc/shadow": "root:!::0:::::\n",
+			},
+			expect: map[string]string{
+				"/etc/passwd": "root:x:0:0:root:/root:/bin/sh\n" +
+					"foo:x:1000:1000::/home/foo:/bin/bash\n",
+				"/etc/group": "root:x:0:\nwheel:x:10:root,foo\n" +
+					"foo:x:1000:\n",
+			},
+		},
+		{
+			name: "WithoutShadow",
+			files: map[string]string{
+				"/etc/passwd": "root::0:0:root:/root:/bin/sh\n",
+				"/etc/group":  "root:x:0:\nwheel:x:10:\n",
+			},
+			expect: map[string]string{
+				"/etc/passwd": "root::0:0:root:/root:/bin/sh\n" +
+					"foo::1000:1000::/home/foo:/bin/bash\n",
+				"/etc/group": "root:x:0:\nwheel:x:10:foo\n" +
+					"foo:x:1000:\n",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := setUpRoot(t, tc.files)
+			defer os.RemoveAll(root)
+
+			manager := users.NewFilesManager(root)
+
+			err := manager.AddUser(&users.User{
+				Name:   "foo",
+				UID:    1000,
+				GID:    1000,
+				Home:   "/home/foo",
+				Shell:  "/bin/bash",
+				Groups: []string{"wheel"},
+			})
+			assert.NoError(t, err)
+
+			for file, content := range tc.expect {
+				assert.Equal(t, content, readFile(t, root, file))
+			}
+		})
+	}
+}
+
+func TestFilesManagerModifyUser(t *testing.T) {
+	root := setUpRoot(t, map[string]string{
+		"/etc/passwd": "root:x:0:0:root:/root:/bin/sh\nfoo:x:1001:1001::/home/foo:/bin/sh\n",
+		"/etc/group":  "root:x:0:\nwheel:x:10:foo\nfoo:x:1001:\n",
+	})
+	defer os.RemoveAll(root)
+
+	manager := users.NewFilesManager(root)
+
+	err := manager.ModifyUser(&users.User{
+		Name:   "foo",
+		UID:    1000,
+		GID:    1000,
+		Home:   "/var/home/foo",
+		Shell:  "/bin/zsh",
+		Groups: []string{"wheel"},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t,
+		"root:x:0:0:root:/root:/bin/sh\nfoo:x:1000:1001::/var/home/foo:/bin/zsh\n",
+		readFile(t, root, "/etc/passwd"))
+	assert.Equal(t, "root:x:0:\nwheel:x:10:foo\nfoo:x:1001:\n", readFile(t, root, "/etc/group"))
+
+	err = manager.ModifyUser(&users.User{Name: "bar", UID: 1002})
+	assert.Error(t, err)
+}
+
+func TestFilesManagerDeletePassword(t *testing.T) {
+	root := setUpRoot(t, map[string]string{
+		"/etc/passwd": "root:x:0:0:root:/root:/bin/sh\n",
+		"/etc/shadow": "root:$6$salt$hash:18000:0:99999:7:::\n",
+	})
+	defer os.RemoveAll(root)
+
+	manager := users.NewFilesManager(root)
+
+	assert.NoError(t, manager.DeletePassword("root"))
+	assert.True(t, strings.HasPrefix(readFile(t, root, "/etc/shadow"), "root::18000:"))
+	assert.Equal(t, "root:x:0:0:root:/root:/bin/sh\n", readFile(t, root, "/etc/passwd"))
+
+	assert.Error(t, manager.DeletePassword("foo"))
+}
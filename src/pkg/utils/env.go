@@ -0,0 +1,172 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	preservedEnvironmentVariablesAllow []string
+	preservedEnvironmentVariablesDeny  []string
+)
+
+// IsEnvironmentVariablePreserved tells whether the variable called name is
+// forwarded from the host to toolbox containers and back. That's the case if
+// it's one of the built-in variables or matches an allowed pattern, and
+// doesn't match a denied pattern.
+func IsEnvironmentVariablePreserved(name string) bool {
+	for _, pattern := range preservedEnvironmentVariablesDeny {
+		if matched, _ := path.Match(pattern, name); matched {
+			return false
+		}
+	}
+
+	for _, variable := range preservedEnvironmentVariables {
+		if name == variable {
+			return true
+		}
+	}
+
+	for _, pattern := range preservedEnvironmentVariablesAllow {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseEnv checks that env is a valid environment variable assignment like
+// KEY=VALUE. A bare KEY takes its value from the current environment, like
+// with 'podman run --env'. The assignment is returned as KEY=VALUE, and an
+// empty string is returned if a bare KEY isn't set.
+func ParseEnv(env string) (string, error) {
+	key := env
+	if i := strings.IndexRune(env, '='); i != -1 {
+		key = env[:i]
+	}
+
+	if key == "" || strings.ContainsAny(key, " \t") {
+		return "", fmt.Errorf("invalid environment variable %s", env)
+	}
+
+	if key != env {
+		return env, nil
+	}
+
+	value, found := os.LookupEnv(key)
+	if !found {
+		return "", nil
+	}
+
+	return key + "=" + value, nil
+}
+
+// SetPreservedEnvironmentVariables changes the set of environment variables
+// that are forwarded. Variables matching the glob patterns in allow, like
+// XDG_* or *_PROXY, are forwarded in addition to the built-in ones, and those
+// matching the patterns in deny aren't forwarded, even if they are built in.
+func SetPreservedEnvironmentVariables(allow, deny []string) error {
+	for _, patterns := range [][]string{allow, deny} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+				return fmt.Errorf("invalid environment variable pattern %s", pattern)
+			}
+		}
+	}
+
+	preservedEnvironmentVariablesAllow = allow
+	preservedEnvironmentVariablesDeny = deny
+	return nil
+}
+
+// getEnvOptions returns --env options for Podman and flatpak-spawn(1) for the
+// variables in the current environment that are accepted by filter, sorted by
+// name.
+func getEnvOptions(filter func(variable string) bool) []string {
+	var variables []string
+
+	for _, env := range os.Environ() {
+		variable := env
+		if i := strings.IndexRune(env, '='); i != -1 {
+			variable = env[:i]
+		}
+
+		if variable == "" || !filter(variable) {
+			continue
+		}
+
+		variables = append(variables, variable)
+	}
+
+	sort.Strings(variables)
+
+	var envOptions []string
+
+	for _, variable := range variables {
+		value := os.Getenv(variable)
+		logrus.Debugf("%s=%s", variable, value)
+		envOptions = append(envOptions, fmt.Sprintf("--env=%s=%s", variable, value))
+	}
+
+	return envOptions
+}
+
+// ParseEnvFile reads environment variable assignments from the file at path,
+// one per line, as understood by ParseEnv. Empty lines and lines starting with
+// a '#' are ignored.
+func ParseEnvFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open environment file %s: %w", path, err)
+	}
+
+	defer file.Close()
+
+	var envs []string
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimLeft(scanner.Text(), " \t")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		env, err := ParseEnv(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+		}
+
+		if env != "" {
+			envs = append(envs, env)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read environment file %s: %w", path, err)
+	}
+
+	return envs, nil
+}
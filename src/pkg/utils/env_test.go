@@ -0,0 +1,131 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEnv(t *testing.T) {
+	require.NoError(t, os.Setenv("TOOLBOX_TEST_ENV", "from-host"))
+	defer os.Unsetenv("TOOLBOX_TEST_ENV")
+
+	os.Unsetenv("TOOLBOX_TEST_UNSET")
+
+	testCases := []struct {
+		env      string
+		expected string
+		err      bool
+	}{
+		{"FOO=bar", "FOO=bar", false},
+		{"FOO=", "FOO=", false},
+		{"FOO=bar=baz", "FOO=bar=baz", false},
+		{"TOOLBOX_TEST_ENV", "TOOLBOX_TEST_ENV=from-host", false},
+		{"TOOLBOX_TEST_UNSET", "", false},
+		{"=bar", "", true},
+		{"FOO BAR=baz", "", true},
+		{"", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.env, func(t *testing.T) {
+			env, err := utils.ParseEnv(tc.env)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, env)
+		})
+	}
+}
+
+func TestParseEnvFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "toolbox-env-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, os.Setenv("TOOLBOX_TEST_ENV", "from-host"))
+	defer os.Unsetenv("TOOLBOX_TEST_ENV")
+
+	path := filepath.Join(dir, "env")
+	content := "# Comment\n\nFOO=bar\n  BAZ=qux quux\nTOOLBOX_TEST_ENV\n"
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+
+	envs, err := utils.ParseEnvFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"FOO=bar", "BAZ=qux quux", "TOOLBOX_TEST_ENV=from-host"}, envs)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("FOO=bar\n=baz\n"), 0644))
+
+	_, err = utils.ParseEnvFile(path)
+	assert.EqualError(t, err, path+":2: invalid environment variable =baz")
+
+	_, err = utils.ParseEnvFile(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestPreservedEnvironmentVariables(t *testing.T) {
+	err := utils.SetPreservedEnvironmentVariables([]string{"*_PROXY", "EDITOR", "KUBECONFIG"},
+		[]string{"XDG_SESSION_*", "NO_PROXY"})
+	require.NoError(t, err)
+	defer utils.SetPreservedEnvironmentVariables(nil, nil)
+
+	testCases := []struct {
+		variable  string
+		preserved bool
+	}{
+		{"TERM", true},
+		{"XDG_RUNTIME_DIR", true},
+		{"XDG_SESSION_TYPE", false},
+		{"HTTP_PROXY", true},
+		{"NO_PROXY", false},
+		{"EDITOR", true},
+		{"KUBECONFIG", true},
+		{"PATH", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.variable, func(t *testing.T) {
+			assert.Equal(t, tc.preserved, utils.IsEnvironmentVariablePreserved(tc.variable))
+		})
+	}
+
+	require.NoError(t, os.Setenv("HTTPS_PROXY", "http://proxy.example.com:3128"))
+	defer os.Unsetenv("HTTPS_PROXY")
+
+	require.NoError(t, os.Setenv("NO_PROXY", "localhost"))
+	defer os.Unsetenv("NO_PROXY")
+
+	envOptions := utils.GetEnvOptionsForPreservedVariables()
+	assert.Contains(t, envOptions, "--env=HTTPS_PROXY=http://proxy.example.com:3128")
+	assert.NotContains(t, envOptions, "--env=NO_PROXY=localhost")
+
+	envOptions = utils.GetEnvOptionsForMinimalVariables()
+	assert.NotContains(t, envOptions, "--env=HTTPS_PROXY=http://proxy.example.com:3128")
+
+	err = utils.SetPreservedEnvironmentVariables([]string{"[A-Z"}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,218 @@
+/*
+ * Copyright © 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ImageReference is a reference to an image split into its parts, following
+// the grammar used by github.com/distribution/distribution/reference:
+//
+//	reference := name [ ":" tag ] [ "@" digest ]
+//	name      := [ domain "/" ] path-component [ "/" path-component ]*
+//
+// Domain, Tag and Digest are empty if they're not part of the reference.
+type ImageReference struct {
+	Domain string
+	Path   string
+	Tag    string
+	Digest string
+}
+
+const (
+	// ImageReferenceDefaultTag is the tag used by Podman for references
+	// without a tag or a digest
+	ImageReferenceDefaultTag = "latest"
+
+	imageReferenceNameMaxLength = 255
+)
+
+var (
+	imageReferenceDigestRegexp = regexp.MustCompile(
+		`^[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,}$`)
+
+	imageReferenceDomainRegexp = regexp.MustCompile(
+		`^(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])` +
+			`(?:\.(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9]))*` +
+			`(?::[0-9]+)?$`)
+
+	imageReferenceIDRegexp = regexp.MustCompile(`^[a-f0-9]{6,64}$`)
+
+	imageReferencePathComponentRegexp = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*$`)
+
+	imageReferenceTagRegexp = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+)
+
+// ParseImageReference splits image into its parts, and fails if it's not a
+// valid reference.
+func ParseImageReference(image string) (*ImageReference, error) {
+	if image == "" {
+		return nil, errors.New("image reference is empty")
+	}
+
+	var reference ImageReference
+	name := image
+
+	if i := strings.IndexRune(name, '@'); i != -1 {
+		reference.Digest = name[i+1:]
+		name = name[:i]
+
+		if !imageReferenceDigestRegexp.MatchString(reference.Digest) {
+			return nil, fmt.Errorf("invalid digest in image reference %s", image)
+		}
+	}
+
+	// A colon after the last slash separates the tag, while one before it
+	// separates the port of the domain
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		reference.Tag = name[i+1:]
+		name = name[:i]
+
+		if !imageReferenceTagRegexp.MatchString(reference.Tag) {
+			return nil, fmt.Errorf("invalid tag in image reference %s", image)
+		}
+	}
+
+	if len(name) > imageReferenceNameMaxLength {
+		return nil, fmt.Errorf("image reference %s is longer than %d characters", image, imageReferenceNameMaxLength)
+	}
+
+	reference.Path = name
+
+	if i := strings.IndexRune(name, '/'); i != -1 {
+		domain := name[:i]
+
+		// A domain should contain a top level domain name or a port. An
+		// exception is 'localhost'.
+		if strings.ContainsAny(domain, ".:") || domain == "localhost" {
+			if !imageReferenceDomainRegexp.MatchString(domain) {
+				return nil, fmt.Errorf("invalid domain in image reference %s", image)
+			}
+
+			reference.Domain = domain
+			reference.Path = name[i+1:]
+		}
+	}
+
+	for _, component := range strings.Split(reference.Path, "/") {
+		if !imageReferencePathComponentRegexp.MatchString(component) {
+			return nil, fmt.Errorf("invalid repository in image reference %s", image)
+		}
+	}
+
+	return &reference, nil
+}
+
+// Basename returns the last component of the path, like fedora-toolbox for
+// registry.fedoraproject.org/f33/fedora-toolbox:33.
+func (reference *ImageReference) Basename() string {
+	return path.Base(reference.Path)
+}
+
+// Name returns the reference without its tag and digest.
+func (reference *ImageReference) Name() string {
+	if reference.Domain == "" {
+		return reference.Path
+	}
+
+	return reference.Domain + "/" + reference.Path
+}
+
+// String returns the reference in its canonical form.
+func (reference *ImageReference) String() string {
+	image := reference.Name()
+
+	if reference.Tag != "" {
+		image += ":" + reference.Tag
+	}
+
+	if reference.Digest != "" {
+		image += "@" + reference.Digest
+	}
+
+	return image
+}
+
+// TagOrDefault returns the tag, or the default tag if the reference has
+// neither a tag nor a digest.
+func (reference *ImageReference) TagOrDefault() string {
+	if reference.Tag == "" && reference.Digest == "" {
+		return ImageReferenceDefaultTag
+	}
+
+	return reference.Tag
+}
+
+// ImageReferenceCanBeID checks if 'image' might be the ID of an image
+func ImageReferenceCanBeID(image string) bool {
+	return imageReferenceIDRegexp.MatchString(image)
+}
+
+// ImageReferenceGetBasename returns the last component of the path of image,
+// or an empty string if image is not a valid reference.
+func ImageReferenceGetBasename(image string) string {
+	reference, err := ParseImageReference(image)
+	if err != nil {
+		return ""
+	}
+
+	return reference.Basename()
+}
+
+// ImageReferenceGetDigest returns the digest of image, or an empty string if
+// there's none or image is not a valid reference.
+func ImageReferenceGetDigest(image string) string {
+	reference, err := ParseImageReference(image)
+	if err != nil {
+		return ""
+	}
+
+	return reference.Digest
+}
+
+// ImageReferenceGetDomain returns the domain of image, or an empty string if
+// there's none or image is not a valid reference.
+func ImageReferenceGetDomain(image string) string {
+	reference, err := ParseImageReference(image)
+	if err != nil {
+		return ""
+	}
+
+	return reference.Domain
+}
+
+// ImageReferenceGetTag returns the tag of image, or an empty string if there's
+// none or image is not a valid reference.
+func ImageReferenceGetTag(image string) string {
+	reference, err := ParseImageReference(image)
+	if err != nil {
+		return ""
+	}
+
+	return reference.Tag
+}
+
+// ImageReferenceHasDomain checks if the provided image has a domain definition in it.
+func ImageReferenceHasDomain(image string) bool {
+	domain := ImageReferenceGetDomain(image)
+	return domain != ""
+}
@@ -0,0 +1,153 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestParseImageReference(t *testing.T) {
+	testCases := []struct {
+		image     string
+		reference utils.ImageReference
+	}{
+		{
+			image:     "fedora-toolbox",
+			reference: utils.ImageReference{Path: "fedora-toolbox"},
+		},
+		{
+			image:     "fedora-toolbox:34",
+			reference: utils.ImageReference{Path: "fedora-toolbox", Tag: "34"},
+		},
+		{
+			image: "registry.fedoraproject.org/f33/fedora-toolbox:33",
+			reference: utils.ImageReference{
+				Domain: "registry.fedoraproject.org",
+				Path:   "f33/fedora-toolbox",
+				Tag:    "33",
+			},
+		},
+		{
+			image:     "localhost:5000/foo",
+			reference: utils.ImageReference{Domain: "localhost:5000", Path: "foo"},
+		},
+		{
+			image:     "localhost/foo:latest",
+			reference: utils.ImageReference{Domain: "localhost", Path: "foo", Tag: "latest"},
+		},
+		{
+			image:     "library/ubuntu",
+			reference: utils.ImageReference{Path: "library/ubuntu"},
+		},
+		{
+			image: "quay.io/toolbx/images/arch-toolbox@" + testDigest,
+			reference: utils.ImageReference{
+				Domain: "quay.io",
+				Path:   "toolbx/images/arch-toolbox",
+				Digest: testDigest,
+			},
+		},
+		{
+			image: "registry.example.com:8443/foo_bar/baz__qux:v1.0-rc1@" + testDigest,
+			reference: utils.ImageReference{
+				Domain: "registry.example.com:8443",
+				Path:   "foo_bar/baz__qux",
+				Tag:    "v1.0-rc1",
+				Digest: testDigest,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			reference, err := utils.ParseImageReference(tc.image)
+			require.NoError(t, err)
+			assert.Equal(t, tc.reference, *reference)
+			assert.Equal(t, tc.image, reference.String())
+		})
+	}
+}
+
+func TestParseImageReferenceInvalid(t *testing.T) {
+	testCases := []string{
+		"",
+		"Fedora-Toolbox",
+		"fedora-toolbox:",
+		"fedora-toolbox:-34",
+		"fedora-toolbox@sha256:1234",
+		"foo//bar",
+		"-foo",
+		"registry.example.com:port/foo",
+		"registry.example.com/foo:34:35",
+	}
+
+	for _, image := range testCases {
+		t.Run(image, func(t *testing.T) {
+			_, err := utils.ParseImageReference(image)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestImageReferenceHelpers(t *testing.T) {
+	assert.Equal(t, "", utils.ImageReferenceGetTag("localhost:5000/foo"))
+	assert.Equal(t, "localhost:5000", utils.ImageReferenceGetDomain("localhost:5000/foo"))
+	assert.Equal(t, "foo", utils.ImageReferenceGetBasename("localhost:5000/foo"))
+
+	assert.Equal(t, "33", utils.ImageReferenceGetTag("registry.fedoraproject.org/f33/fedora-toolbox:33"))
+	assert.Equal(t, "fedora-toolbox", utils.ImageReferenceGetBasename("registry.fedoraproject.org/f33/fedora-toolbox:33"))
+
+	assert.Equal(t, testDigest, utils.ImageReferenceGetDigest("fedora-toolbox@"+testDigest))
+	assert.Equal(t, "", utils.ImageReferenceGetTag("fedora-toolbox@"+testDigest))
+
+	assert.False(t, utils.ImageReferenceHasDomain("library/ubuntu"))
+	assert.True(t, utils.ImageReferenceHasDomain("localhost/foo"))
+
+	assert.True(t, utils.ImageReferenceCanBeID("c2b4c8ff0ad1"))
+	assert.True(t, utils.ImageReferenceCanBeID("0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"))
+	assert.False(t, utils.ImageReferenceCanBeID("c2b4c"))
+	assert.False(t, utils.ImageReferenceCanBeID("fedora-toolbox"))
+}
+
+func TestImageReferenceTagOrDefault(t *testing.T) {
+	reference, err := utils.ParseImageReference("fedora-toolbox")
+	require.NoError(t, err)
+	assert.Equal(t, utils.ImageReferenceDefaultTag, reference.TagOrDefault())
+
+	reference, err = utils.ParseImageReference("fedora-toolbox@" + testDigest)
+	require.NoError(t, err)
+	assert.Equal(t, "", reference.TagOrDefault())
+}
+
+func TestResolveContainerAndImageNames(t *testing.T) {
+	testCases := []struct {
+		image     string
+		container string
+		release   string
+	}{
+		{"fedora-toolbox:34", "fedora-toolbox-34", "34"},
+		{"localhost:5000/foo:1.0", "foo-1.0", "1.0"},
+		{"quay.io/toolbx/arch-toolbox@" + testDigest, "arch-toolbox-0123456789ab", ""},
+		{"quay.io/toolbx/arch-toolbox:rolling@" + testDigest, "arch-toolbox-rolling", "rolling"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			container, image, release, err := utils.ResolveContainerAndImageNames("", "", tc.image, "")
+			require.NoError(t, err)
+			assert.Equal(t, tc.container, container)
+			assert.Equal(t, tc.image, image)
+
+			if tc.release != "" {
+				assert.Equal(t, tc.release, release)
+			}
+		})
+	}
+
+	_, _, _, err := utils.ResolveContainerAndImageNames("", "", "Not/A/Valid:Image", "")
+	assert.Error(t, err)
+}
@@ -19,11 +19,11 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"os/user"
 	"path"
-	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
@@ -32,9 +32,11 @@ import (
 	"time"
 
 	"github.com/acobaugh/osrelease"
+	"github.com/containers/toolbox/pkg/manual"
 	"github.com/containers/toolbox/pkg/shell"
 	"github.com/docker/go-units"
 	"github.com/godbus/dbus/v5"
+	"github.com/mattn/go-isatty"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sys/unix"
 )
@@ -64,6 +66,18 @@ const (
 var (
 	distroDefault = "fedora"
 
+	// minimalEnvironmentVariables is the subset of
+	// preservedEnvironmentVariables used for a clean environment
+	minimalEnvironmentVariables = []string{
+		"COLORTERM",
+		"LANG",
+		"TERM",
+		"TOOLBOX_PATH",
+		"USER",
+	}
+
+	// preservedEnvironmentVariables can be extended and restricted with
+	// SetPreservedEnvironmentVariables
 	preservedEnvironmentVariables = []string{
 		"COLORTERM",
 		"DBUS_SESSION_BUS_ADDRESS",
@@ -136,10 +150,16 @@ func init() {
 }
 
 func AskForConfirmation(prompt string) bool {
+	return AskForConfirmationOn(os.Stdout, prompt)
+}
+
+// AskForConfirmationOn is like AskForConfirmation, but shows the prompt on
+// writer instead of the standard output.
+func AskForConfirmationOn(writer io.Writer, prompt string) bool {
 	var retVal bool
 
 	for {
-		fmt.Printf("%s ", prompt)
+		fmt.Fprintf(writer, "%s ", prompt)
 
 		var response string
 
@@ -301,23 +321,44 @@ func GetDefaultImageForDistro(distro, release string) string {
 	return image
 }
 
+// GetDistroForImage returns the supported distribution that image belongs to,
+// going by its basename.
+func GetDistroForImage(image string) (string, bool) {
+	basename := ImageReferenceGetBasename(image)
+	if basename == "" {
+		return "", false
+	}
+
+	for distro, distroObj := range supportedDistros {
+		if distroObj.ImageBasename == basename {
+			return distro, true
+		}
+	}
+
+	return "", false
+}
+
 func GetEnvOptionsForPreservedVariables() []string {
 	logrus.Debug("Creating list of environment variables to forward")
 
-	var envOptions []string
+	return getEnvOptions(IsEnvironmentVariablePreserved)
+}
 
-	for _, variable := range preservedEnvironmentVariables {
-		value, found := os.LookupEnv(variable)
-		if !found {
-			logrus.Debugf("%s is unset", variable)
-			continue
-		}
+// GetEnvOptionsForMinimalVariables is like GetEnvOptionsForPreservedVariables,
+// but only forwards the few variables that are needed for a usable terminal
+// and for Toolbox to work inside the container.
+func GetEnvOptionsForMinimalVariables() []string {
+	logrus.Debug("Creating minimal list of environment variables to forward")
 
-		logrus.Debugf("%s=%s", variable, value)
-		envOptions = append(envOptions, fmt.Sprintf("--env=%s=%s", variable, value))
-	}
+	return getEnvOptions(func(variable string) bool {
+		for _, minimalVariable := range minimalEnvironmentVariables {
+			if variable == minimalVariable {
+				return true
+			}
+		}
 
-	return envOptions
+		return false
+	})
 }
 
 func GetFullyQualifiedImageFromDistros(image, release string) (string, error) {
@@ -491,80 +532,43 @@ func GetRuntimeDirectory(targetUser *user.User) (string, error) {
 	return toolboxRuntimeDirectory, nil
 }
 
-// HumanDuration accepts a Unix time value and converts it into a human readable
-// string.
-//
-// Examples: "5 minutes ago", "2 hours ago", "3 days ago"
-func HumanDuration(duration int64) string {
-	return units.HumanDuration(time.Since(time.Unix(duration, 0))) + " ago"
-}
-
-// ImageReferenceCanBeID checks if 'image' might be the ID of an image
-func ImageReferenceCanBeID(image string) (bool, error) {
-	matched, err := regexp.MatchString("^[a-f0-9]\\{6,64\\}$", image)
-	return matched, err
-}
-
-func ImageReferenceGetBasename(image string) string {
-	var i int
-
-	if ImageReferenceHasDomain(image) {
-		i = strings.IndexRune(image, '/')
-	}
-
-	remainder := image[i:]
-	j := strings.IndexRune(remainder, ':')
-	if j == -1 {
-		j = len(remainder)
-	}
-
-	path := remainder[:j]
-	basename := filepath.Base(path)
-	return basename
-}
+// GetStateDirectory returns the directory for the state that Toolbox keeps
+// across reboots, like when a toolbox container was last used. It follows the
+// XDG Base Directory Specification for $XDG_STATE_HOME, and isn't created.
+func GetStateDirectory() (string, error) {
+	stateDirectory := os.Getenv("XDG_STATE_HOME")
+	if !path.IsAbs(stateDirectory) {
+		homeDirectory, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get the home directory: %w", err)
+		}
 
-func ImageReferenceGetDomain(image string) string {
-	if !ImageReferenceHasDomain(image) {
-		return ""
+		stateDirectory = path.Join(homeDirectory, ".local", "state")
 	}
 
-	i := strings.IndexRune(image, '/')
-	domain := image[:i]
-	return domain
+	toolboxStateDirectory := path.Join(stateDirectory, "toolbox")
+	return toolboxStateDirectory, nil
 }
 
-func ImageReferenceGetTag(image string) string {
-	var i int
+// GetSupportedDistros returns the names of the supported distributions in
+// alphabetical order.
+func GetSupportedDistros() []string {
+	var distros []string
 
-	if ImageReferenceHasDomain(image) {
-		i = strings.IndexRune(image, '/')
+	for distro := range supportedDistros {
+		distros = append(distros, distro)
 	}
 
-	remainder := image[i:]
-	j := strings.IndexRune(remainder, ':')
-	if j == -1 {
-		return ""
-	}
-
-	tag := remainder[j+1:]
-	return tag
+	sort.Strings(distros)
+	return distros
 }
 
-// ImageReferenceHasDomain checks if the provided image has a domain definition in it.
-func ImageReferenceHasDomain(image string) bool {
-	i := strings.IndexRune(image, '/')
-	if i == -1 {
-		return false
-	}
-
-	prefix := image[:i]
-
-	// A domain should contain a top level domain name. An exception is 'localhost'
-	if !strings.ContainsAny(prefix, ".:") && prefix != "localhost" {
-		return false
-	}
-
-	return true
+// HumanDuration accepts a Unix time value and converts it into a human readable
+// string.
+//
+// Examples: "5 minutes ago", "2 hours ago", "3 days ago"
+func HumanDuration(duration int64) string {
+	return units.HumanDuration(time.Since(time.Unix(duration, 0))) + " ago"
 }
 
 // ShortID shortens provided id to first 12 characters.
@@ -717,25 +721,36 @@ func ResolveContainerAndImageNames(container, distro, image, release string) (st
 		release = releaseDefault
 	}
 
-	if image == "" {
+	imageIsDefault := image == ""
+	if imageIsDefault {
 		image = GetDefaultImageForDistro(distro, release)
-	} else {
-		release = ImageReferenceGetTag(image)
+	}
+
+	reference, err := ParseImageReference(image)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	if !imageIsDefault {
+		release = reference.Tag
 		if release == "" {
 			release = releaseDefault
 		}
 	}
 
 	if container == "" {
-		var err error
 		container, err = GetContainerNamePrefixForImage(image)
 		if err != nil {
 			return "", "", "", err
 		}
 
-		tag := ImageReferenceGetTag(image)
-		if tag != "" {
-			container = container + "-" + tag
+		// Images pinned to a digest without a tag are told apart by a
+		// shortened digest, like 'fedora-toolbox-0123456789ab'
+		if reference.Tag != "" {
+			container = container + "-" + reference.Tag
+		} else if reference.Digest != "" {
+			digest := reference.Digest[strings.IndexRune(reference.Digest, ':')+1:]
+			container = container + "-" + ShortID(digest)
 		}
 	}
 
@@ -747,14 +762,16 @@ func ResolveContainerAndImageNames(container, distro, image, release string) (st
 	return container, image, release, nil
 }
 
+// ShowManual shows a manual with man(1). If man(1) or the manual isn't
+// installed, the manual built into the binary is shown instead.
 func ShowManual(manual string) error {
-	manBinary, err := exec.LookPath("man")
+	manBinary, err := lookUpManual(manual)
 	if err != nil {
-		if errors.Is(err, exec.ErrNotFound) {
-			return errors.New("man(1) not found")
-		}
+		return err
+	}
 
-		return errors.New("failed to lookup man(1)")
+	if manBinary == "" {
+		return showBuiltInManual(manual)
 	}
 
 	manualArgs := []string{"man", manual}
@@ -775,6 +792,70 @@ func ShowManual(manual string) error {
 	return nil
 }
 
+// ShowManualFollowedBy shows manual like ShowManual, followed by text. Unlike
+// ShowManual, man(1) is run as a child process, so that text is shown once the
+// pager is closed, instead of being hidden behind it.
+func ShowManualFollowedBy(manual, text string) error {
+	if text == "" {
+		return ShowManual(manual)
+	}
+
+	manBinary, err := lookUpManual(manual)
+	if err != nil {
+		return err
+	}
+
+	if manBinary == "" {
+		if err := showBuiltInManual(manual); err != nil {
+			return err
+		}
+	} else {
+		if err := shell.Run(manBinary, os.Stdin, os.Stdout, os.Stdout, manual); err != nil {
+			return errors.New("failed to invoke man(1)")
+		}
+	}
+
+	fmt.Printf("%s", text)
+	return nil
+}
+
+// lookUpManual returns the path to man(1) if it can show manual, or an empty
+// string if the built-in manual should be shown instead.
+func lookUpManual(manual string) (string, error) {
+	manBinary, err := exec.LookPath("man")
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			logrus.Debug("man(1) not found: showing the built-in manual")
+			return "", nil
+		}
+
+		return "", errors.New("failed to lookup man(1)")
+	}
+
+	if err := shell.Run("man", nil, nil, nil, "-w", manual); err != nil {
+		logrus.Debugf("Manual %s not found by man(1): showing the built-in manual", manual)
+		return "", nil
+	}
+
+	return manBinary, nil
+}
+
+func showBuiltInManual(name string) error {
+	source, ok := manual.Get(name)
+	if !ok {
+		return fmt.Errorf("manual %s not found", name)
+	}
+
+	stdoutFd := os.Stdout.Fd()
+	styled := isatty.IsTerminal(stdoutFd)
+
+	if err := manual.Render(os.Stdout, source, styled); err != nil {
+		return fmt.Errorf("failed to show manual %s: %w", name, err)
+	}
+
+	return nil
+}
+
 func SortJSON(json []map[string]interface{}, key string, hasInterface bool) []map[string]interface{} {
 	sort.Slice(json, func(i, j int) bool {
 		if hasInterface {
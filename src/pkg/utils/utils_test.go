@@ -0,0 +1,102 @@
+/*
+ * Copyright © 2019 – 2021 Red Hat Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/containers/toolbox/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDistroForImage(t *testing.T) {
+	testCases := []struct {
+		name   string
+		image  string
+		distro string
+		found  bool
+	}{
+		{
+			name:   "Fedora image with a tag",
+			image:  "fedora-toolbox:35",
+			distro: "fedora",
+			found:  true,
+		},
+		{
+			name:   "Fedora image with a registry",
+			image:  "registry.fedoraproject.org/fedora-toolbox:34",
+			distro: "fedora",
+			found:  true,
+		},
+		{
+			name:   "RHEL image",
+			image:  "registry.access.redhat.com/ubi8/ubi:8.4",
+			distro: "rhel",
+			found:  true,
+		},
+		{
+			name:  "Unknown image",
+			image: "quay.io/example/foo:latest",
+			found: false,
+		},
+		{
+			name:  "Invalid reference",
+			image: "Foo:Bar:Baz",
+			found: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			distro, found := utils.GetDistroForImage(tc.image)
+			assert.Equal(t, tc.found, found)
+			assert.Equal(t, tc.distro, distro)
+		})
+	}
+}
+
+func TestGetSupportedDistros(t *testing.T) {
+	assert.Equal(t, []string{"fedora", "rhel"}, utils.GetSupportedDistros())
+}
+
+func TestGetStateDirectory(t *testing.T) {
+	stateHome, stateHomeSet := os.LookupEnv("XDG_STATE_HOME")
+	defer func() {
+		if stateHomeSet {
+			os.Setenv("XDG_STATE_HOME", stateHome)
+		} else {
+			os.Unsetenv("XDG_STATE_HOME")
+		}
+	}()
+
+	os.Setenv("XDG_STATE_HOME", "/tmp/state")
+
+	stateDirectory, err := utils.GetStateDirectory()
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/state/toolbox", stateDirectory)
+
+	// Relative paths are invalid according to the specification
+	os.Setenv("XDG_STATE_HOME", "state")
+
+	homeDirectory, err := os.UserHomeDir()
+	assert.NoError(t, err)
+
+	stateDirectory, err = utils.GetStateDirectory()
+	assert.NoError(t, err)
+	assert.Equal(t, homeDirectory+"/.local/state/toolbox", stateDirectory)
+}